@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -9,9 +10,18 @@ import (
 
 type Config struct {
 	Server      Server
+	GRPC        GRPC
 	MongoDB     MongoDB
 	Redis       Redis
 	Kafka       Kafka
+	Archive     Archive
+	Tenant      Tenant
+	Auth        Auth
+	APIKeys     APIKeys
+	RateLimit   RateLimit
+	Events      Events
+	Webhooks    Webhooks
+	APIVersions APIVersions
 	Environment string `envconfig:"ENVIRONMENT" default:"production"`
 	LogLevel    string `envconfig:"LOG_LEVEL" default:"info"`
 }
@@ -21,6 +31,16 @@ type Server struct {
 	ReadTimeout      time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"30s"`
 	WriteTimeout     time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"30s"`
 	GracefulShutdown time.Duration `envconfig:"GRACEFUL_SHUTDOWN" default:"10s"`
+	// TrustedProxies are the addresses or CIDR ranges of the proxies whose X-Forwarded-For
+	// header is used as the client IP. The peer address is used when empty.
+	TrustedProxies []string `envconfig:"SERVER_TRUSTED_PROXIES"`
+}
+
+type GRPC struct {
+	Enabled       bool          `envconfig:"GRPC_ENABLED" default:"true"`
+	Port          string        `envconfig:"GRPC_PORT" default:"9090"`
+	Reflection    bool          `envconfig:"GRPC_REFLECTION" default:"true"`
+	WatchInterval time.Duration `envconfig:"GRPC_WATCH_INTERVAL" default:"2s"`
 }
 
 type MongoDB struct {
@@ -44,6 +64,97 @@ type Kafka struct {
 	Topic   string   `envconfig:"KAFKA_TOPIC" default:"order_events"`
 }
 
+type Archive struct {
+	Enabled    bool          `envconfig:"ARCHIVE_ENABLED" default:"true"`
+	Collection string        `envconfig:"ARCHIVE_COLLECTION" default:"orders_archive"`
+	Retention  time.Duration `envconfig:"ARCHIVE_RETENTION" default:"2160h"`
+	Interval   time.Duration `envconfig:"ARCHIVE_INTERVAL" default:"1h"`
+	BatchSize  int           `envconfig:"ARCHIVE_BATCH_SIZE" default:"500"`
+}
+
+type Tenant struct {
+	Header  string `envconfig:"TENANT_HEADER" default:"X-Tenant-ID"`
+	Default string `envconfig:"TENANT_DEFAULT"`
+}
+
+type Auth struct {
+	Enabled     bool          `envconfig:"AUTH_ENABLED" default:"true"`
+	JWKS        string        `envconfig:"AUTH_JWKS"`
+	JWKSRefresh time.Duration `envconfig:"AUTH_JWKS_REFRESH" default:"15m"`
+	Issuer      string        `envconfig:"AUTH_ISSUER"`
+	Audience    string        `envconfig:"AUTH_AUDIENCE"`
+	Leeway      time.Duration `envconfig:"AUTH_LEEWAY" default:"30s"`
+	PolicyFile  string        `envconfig:"AUTH_POLICY_FILE"`
+	// TenantClaim is the token claim holding the tenant the caller belongs to
+	TenantClaim string `envconfig:"AUTH_TENANT_CLAIM" default:"tenant_id"`
+}
+
+// APIKeys configures the API keys of the partner systems, sent in Header. A rotated key keeps
+// working for RotationGrace, and the last use of a key is recorded at most every
+// LastUsedInterval.
+type APIKeys struct {
+	Enabled          bool          `envconfig:"API_KEYS_ENABLED" default:"true"`
+	Collection       string        `envconfig:"API_KEYS_COLLECTION" default:"api_keys"`
+	Header           string        `envconfig:"API_KEY_HEADER" default:"X-API-Key"`
+	RotationGrace    time.Duration `envconfig:"API_KEY_ROTATION_GRACE" default:"24h"`
+	LastUsedInterval time.Duration `envconfig:"API_KEY_LAST_USED_INTERVAL" default:"1m"`
+}
+
+// RateLimit configures the request limits of each client, identified by its API key or else
+// its IP address, per route group. Limits maps the route groups to their requests per Window,
+// and Overrides maps "<API key ID or IPv4 address>/<group>" to the limit of a single client.
+type RateLimit struct {
+	Enabled   bool           `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+	Window    time.Duration  `envconfig:"RATE_LIMIT_WINDOW" default:"1m"`
+	Timeout   time.Duration  `envconfig:"RATE_LIMIT_TIMEOUT" default:"100ms"`
+	Limits    map[string]int `envconfig:"RATE_LIMIT_LIMITS" default:"orders:600,order-writes:60,graphql:300,admin:30,exports:10,auth-failures:20"`
+	Overrides map[string]int `envconfig:"RATE_LIMIT_OVERRIDES"`
+}
+
+// Events configures the live stream of the order status changes. Changes are appended to a
+// Redis stream of at most StreamMaxLen entries, read by every instance and pushed to its
+// subscribers, which can resume from the last event they received.
+type Events struct {
+	Enabled      bool          `envconfig:"EVENTS_ENABLED" default:"true"`
+	Stream       string        `envconfig:"EVENTS_STREAM" default:"order_events"`
+	StreamMaxLen int64         `envconfig:"EVENTS_STREAM_MAX_LEN" default:"10000"`
+	Heartbeat    time.Duration `envconfig:"EVENTS_HEARTBEAT" default:"15s"`
+	// Buffer is the number of events kept for a slow subscriber before it is disconnected
+	Buffer int `envconfig:"EVENTS_BUFFER" default:"64"`
+	// ReplayLimit is the maximum number of missed events read at a time when a subscriber resumes
+	ReplayLimit int64 `envconfig:"EVENTS_REPLAY_LIMIT" default:"1000"`
+	// MaxSubscriptions is the maximum number of orders a WebSocket connection subscribes to
+	MaxSubscriptions int `envconfig:"EVENTS_MAX_SUBSCRIPTIONS" default:"100"`
+}
+
+// Webhooks configures the HTTP callbacks of the order events. A failed delivery is retried
+// after a backoff doubling from InitialBackoff up to MaxBackoff, and moved to the dead letters
+// after MaxAttempts. Delivery logs are kept for Retention.
+type Webhooks struct {
+	Enabled              bool          `envconfig:"WEBHOOKS_ENABLED" default:"true"`
+	Collection           string        `envconfig:"WEBHOOKS_COLLECTION" default:"webhooks"`
+	DeliveriesCollection string        `envconfig:"WEBHOOK_DELIVERIES_COLLECTION" default:"webhook_deliveries"`
+	Timeout              time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	MaxAttempts          int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	InitialBackoff       time.Duration `envconfig:"WEBHOOK_INITIAL_BACKOFF" default:"30s"`
+	MaxBackoff           time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+	PollInterval         time.Duration `envconfig:"WEBHOOK_POLL_INTERVAL" default:"5s"`
+	BatchSize            int           `envconfig:"WEBHOOK_BATCH_SIZE" default:"20"`
+	Retention            time.Duration `envconfig:"WEBHOOK_RETENTION" default:"720h"`
+	// AllowInsecure accepts plain HTTP URLs, for local development
+	AllowInsecure bool `envconfig:"WEBHOOK_ALLOW_INSECURE" default:"false"`
+	// AllowPrivateNetworks delivers to loopback, private and link-local addresses, for local
+	// development
+	AllowPrivateNetworks bool `envconfig:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" default:"false"`
+}
+
+// APIVersions holds the lifecycle of the deprecated REST API versions, announced in the
+// Deprecation and Sunset headers of their responses
+type APIVersions struct {
+	V1DeprecatedAt time.Time `envconfig:"API_V1_DEPRECATED_AT" default:"2026-10-01T00:00:00Z"`
+	V1Sunset       time.Time `envconfig:"API_V1_SUNSET" default:"2027-04-01T00:00:00Z"`
+}
+
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 
@@ -52,10 +163,56 @@ func LoadConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
+// validate checks the settings the services cannot run with
+func (c *Config) validate() error {
+	if err := c.Archive.validate(); err != nil {
+		return err
+	}
+	// The event streams and the order sockets tick at the heartbeat
+	if err := positive("EVENTS_HEARTBEAT", c.Events.Heartbeat); err != nil {
+		return err
+	}
+	if c.Events.ReplayLimit <= 0 {
+		return fmt.Errorf("EVENTS_REPLAY_LIMIT must be positive, got %d", c.Events.ReplayLimit)
+	}
+	if err := positive("WEBHOOK_POLL_INTERVAL", c.Webhooks.PollInterval); err != nil {
+		return err
+	}
+	if err := positive("GRPC_WATCH_INTERVAL", c.GRPC.WatchInterval); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a Archive) validate() error {
+	if err := positive("ARCHIVE_INTERVAL", a.Interval); err != nil {
+		return err
+	}
+	// A zero retention would archive every finished order right away
+	if err := positive("ARCHIVE_RETENTION", a.Retention); err != nil {
+		return err
+	}
+	if a.BatchSize <= 0 {
+		return fmt.Errorf("ARCHIVE_BATCH_SIZE must be positive, got %d", a.BatchSize)
+	}
+	return nil
+}
+
+// positive checks that the duration of the setting is above zero
+func positive(name string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive, got %s", name, d)
+	}
+	return nil
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Server.Port == "80" || c.Server.Port == "443"
 }
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+
+	modelsv2 "order-management-ms/src/main/models/api/v2"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IssueAPIKey handles issuing an API key
+// @Summary Issue an API key
+// @Description Issues an API key for a partner system of the tenant. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param input body modelsv2.IssueAPIKeyRequest true "API key data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 201 {object} modelsv2.APIKeyResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/api-keys [post]
+func (c *APIKeyController) IssueAPIKey(ctx *gin.Context) {
+	var req modelsv2.IssueAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
+		return
+	}
+
+	key, err := c.service.IssueKey(ctx.Request.Context(), req.ToDomain())
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to issue API key", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToIssueAPIKey))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys handles listing the API keys
+// @Summary List API keys
+// @Description Lists the API keys of the tenant, newest first, without their secrets
+// @Tags api-keys
+// @Produce json
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.ListAPIKeysResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/api-keys [get]
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+	keys, err := c.service.ListKeys(ctx.Request.Context())
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to list API keys", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// RotateAPIKey handles rotating an API key
+// @Summary Rotate an API key
+// @Description Replaces the secret of an API key. The previous secret keeps working for the rotation grace period.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Key ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.APIKeyResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/api-keys/{id}/rotate [post]
+func (c *APIKeyController) RotateAPIKey(ctx *gin.Context) {
+	keyID := ctx.Param("id")
+
+	key, err := c.service.RotateKey(ctx.Request.Context(), keyID)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to rotate API key", zap.Error(err), zap.String("key_id", keyID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey handles revoking an API key
+// @Summary Revoke an API key
+// @Description Revokes an API key, and its previous secret, immediately
+// @Tags api-keys
+// @Param id path string true "Key ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 204
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	keyID := ctx.Param("id")
+
+	if err := c.service.RevokeKey(ctx.Request.Context(), keyID); err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to revoke API key", zap.Error(err), zap.String("key_id", keyID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,7 +1,13 @@
 package controllers
 
 import (
+	"time"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/services/apikeys"
 	"order-management-ms/src/main/services/orders"
+	"order-management-ms/src/main/services/webhooks"
 
 	"go.uber.org/zap"
 )
@@ -17,3 +23,65 @@ func NewOrderController(service orders.Service, logger *zap.Logger) *OrderContro
 		logger:  logger,
 	}
 }
+
+// OrderControllerV2 serves the version 2 of the order routes over the same service
+type OrderControllerV2 struct {
+	service orders.Service
+	logger  *zap.Logger
+}
+
+func NewOrderControllerV2(service orders.Service, logger *zap.Logger) *OrderControllerV2 {
+	return &OrderControllerV2{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// APIKeyController serves the administration of the API keys
+type APIKeyController struct {
+	service apikeys.Service
+	logger  *zap.Logger
+}
+
+func NewAPIKeyController(service apikeys.Service, logger *zap.Logger) *APIKeyController {
+	return &APIKeyController{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// WebhookController serves the administration of the webhooks and their deliveries
+type WebhookController struct {
+	service webhooks.Service
+	logger  *zap.Logger
+}
+
+func NewWebhookController(service webhooks.Service, logger *zap.Logger) *WebhookController {
+	return &WebhookController{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// OrderEventsController streams the order status changes to the live clients
+type OrderEventsController struct {
+	service orders.Service
+	stream  *events.Stream
+	cfg     config.Events
+	logger  *zap.Logger
+}
+
+func NewOrderEventsController(service orders.Service, stream *events.Stream, cfg config.Events, logger *zap.Logger) *OrderEventsController {
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = 15 * time.Second
+	}
+	if cfg.MaxSubscriptions <= 0 {
+		cfg.MaxSubscriptions = 100
+	}
+	return &OrderEventsController{
+		service: service,
+		stream:  stream,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
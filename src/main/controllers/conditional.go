@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified sets the validators of a resource version, its ETag and Last-Modified headers,
+// and answers 304 Not Modified when the conditional headers of the request show the client
+// already has it. If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if !matchesETag(ifNoneMatch, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since"))
+		if err != nil || lastModified.IsZero() || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// matchesETag compares the entity tags of an If-None-Match header with etag, using the weak
+// comparison
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
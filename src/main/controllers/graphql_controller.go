@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/graphql"
+	"order-management-ms/src/main/services/orders"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"go.uber.org/zap"
+)
+
+type GraphQLController struct {
+	service orders.Service
+	schema  gql.Schema
+	logger  *zap.Logger
+}
+
+// graphQLRequest represents a GraphQL request, sent as a JSON body or as query parameters
+type graphQLRequest struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func NewGraphQLController(service orders.Service, logger *zap.Logger) (*GraphQLController, error) {
+	schema, err := graphql.NewSchema(service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphQLController{
+		service: service,
+		schema:  schema,
+		logger:  logger,
+	}, nil
+}
+
+// Query handles a GraphQL query or mutation
+// @Summary Run a GraphQL query
+// @Description Runs a query or mutation over orders. GET requests only run queries.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param query query string false "GraphQL query, for GET requests"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 405 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /graphql [post]
+// @Router /graphql [get]
+func (c *GraphQLController) Query(ctx *gin.Context) {
+	var req graphQLRequest
+	if ctx.Request.Method == http.MethodGet {
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			_ = ctx.Error(errors.ErrInvalidRequest)
+			return
+		}
+		if variables := ctx.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				_ = ctx.Error(errors.ErrInvalidRequest)
+				return
+			}
+		}
+	} else if err := ctx.ShouldBindJSON(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid GraphQL request body", zap.Error(err))
+		_ = ctx.Error(errors.ErrInvalidRequest)
+		return
+	}
+
+	if req.Query == "" {
+		_ = ctx.Error(errors.ErrInvalidRequest)
+		return
+	}
+
+	// Mutations must not be triggered by a link or a cached GET
+	if ctx.Request.Method == http.MethodGet && isMutation(req) {
+		_ = ctx.Error(errors.ErrMutationRequiresPost)
+		return
+	}
+
+	result := gql.Do(gql.Params{
+		Schema:         c.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        graphql.WithLoaders(ctx.Request.Context(), graphql.NewLoaders(c.service)),
+	})
+	if result.HasErrors() {
+		graphql.ExtendErrors(result)
+		requestLogger(ctx, c.logger).Warn("GraphQL query returned errors", zap.Any("errors", result.Errors))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// isMutation checks if the operation run by the request is a mutation
+func isMutation(req graphQLRequest) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return false
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName != "" && (operation.Name == nil || operation.Name.Value != req.OperationName) {
+			continue
+		}
+		if operation.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
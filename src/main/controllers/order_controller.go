@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	stderrors "errors"
 	"net/http"
 	models "order-management-ms/src/main/models/api"
 	domain "order-management-ms/src/main/models/datastore"
 	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/validation"
 	"strconv"
 	"strings"
 
@@ -18,30 +21,36 @@ import (
 // @Tags orders
 // @Accept json
 // @Produce json
-// @Param input body dtos.CreateOrderRequest true "Order data"
-// @Success 201 {object} domain.Order
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param input body models.CreateOrderRequest true "Order data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 201 {object} models.OrderResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Deprecated
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /api/v1/orders [post]
 func (c *OrderController) CreateOrder(ctx *gin.Context) {
-	var req *models.CreateOrderRequest
+	var req models.CreateOrderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		c.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.ErrInvalidRequest.Error()})
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
 		return
 	}
 
 	// Validate order
-	if err := c.validateOrder(req); err != nil {
-		c.logger.Error("Invalid order", zap.Error(err), zap.String("	", req.CustomerID))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.validateOrder(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid order", zap.Error(err))
+		_ = ctx.Error(err)
 		return
 	}
 
 	order, err := c.service.CreateOrder(ctx.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.logger.Error("Failed to create order", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrFailedToCreateOrder.Error()})
+		requestLogger(ctx, c.logger).Error("Failed to create order", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToCreateOrder))
 		return
 	}
 
@@ -54,22 +63,42 @@ func (c *OrderController) CreateOrder(ctx *gin.Context) {
 // @Tags orders
 // @Produce json
 // @Param id path string true "Order ID"
-// @Success 200 {object} domain.Order
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param fields query string false "Comma separated fields to return, e.g. order_id,status"
+// @Param expand query string false "Comma separated related resources to inline: history"
+// @Param If-None-Match header string false "ETag of the version held by the client"
+// @Param If-Modified-Since header string false "Last-Modified of the version held by the client"
+// @Success 200 {object} models.OrderResponse
+// @Success 304 "Not modified since the version of If-None-Match or If-Modified-Since"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Deprecated
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /api/v1/orders/{id} [get]
 func (c *OrderController) GetOrder(ctx *gin.Context) {
 	orderID := ctx.Param("id")
 	if orderID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		_ = ctx.Error(errors.ErrInvalidOrderID)
 		return
 	}
 
-	order, err := c.service.GetOrder(ctx.Request.Context(), orderID)
+	read, err := parseReadOptions(ctx)
 	if err != nil {
-		c.logger.Error("Failed to get order", zap.Error(err), zap.String("order_id", orderID))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrOrderNotFound.Error()})
+		requestLogger(ctx, c.logger).Error("Invalid read options", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	order, err := c.service.GetOrder(ctx.Request.Context(), orderID, read)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to get order", zap.Error(err), zap.String("order_id", orderID))
+		_ = ctx.Error(err)
+		return
+	}
+	if notModified(ctx, order.ETag(), order.UpdatedAt) {
 		return
 	}
 
@@ -78,63 +107,106 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 
 // ListOrders handles listing orders
 // @Summary List orders
-// @Description Lists all orders
+// @Description Lists all orders. Pages are selected with page/limit, or with an opaque cursor when the cursor parameter is present
 // @Tags orders
 // @Produce json
-// @Success 200 {object} []domain.Order
-// @Failure 500 {object} map[string]string
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param status query string false "Comma separated order statuses, e.g. NEW,IN_PROGRESS"
+// @Param customer_id query string false "Customer ID"
+// @Param sku query string false "Only orders containing this SKU"
+// @Param created_from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_from query string false "Updated at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_to query string false "Updated at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param min_total query number false "Minimum order total"
+// @Param max_total query number false "Maximum order total"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10)
+// @Param cursor query string false "Cursor returned as next_cursor or prev_cursor, empty for the first page"
+// @Param include_total query bool false "Count the matching orders" default(true)
+// @Param fields query string false "Comma separated fields to return for each order, e.g. order_id,status"
+// @Param expand query string false "Comma separated related resources to inline: history"
+// @Param sort query string false "Comma separated sort keys among created_at, updated_at, status, total and customer_id, prefixed with - for descending order" default(-created_at)
+// @Success 200 {object} models.ListOrdersResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Deprecated
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /api/v1/orders [get]
 func (c *OrderController) ListOrders(ctx *gin.Context) {
-	// Get query parameters
-	status := ctx.Query("status")
-	customerID := ctx.Query("customer_id")
-
-	// Create filters map
-	filters := make(map[string]interface{})
-	if status != "" {
-		filters["status"] = status
-	}
-	if customerID != "" {
-		filters["customer_id"] = customerID
-	}
-
-	page, limit, err := validatePaginationParams(ctx)
+	filter, opts, err := parseListRequest(ctx)
 	if err != nil {
-		c.logger.Error("Invalid pagination parameters", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		requestLogger(ctx, c.logger).Error("Invalid list parameters", zap.Error(err))
+		_ = ctx.Error(err)
 		return
 	}
 
-	c.logger.Debug("Listing orders",
-		zap.Any("filters", filters),
-		zap.Int("page", page),
-		zap.Int("limit", limit),
+	requestLogger(ctx, c.logger).Debug("Listing orders",
+		zap.Any("filter", filter),
+		zap.Int("page", opts.Page),
+		zap.Int("limit", opts.Limit),
+		zap.Bool("cursor_mode", opts.CursorMode),
+		zap.Any("sort", opts.Sort),
 	)
 
-	orders, err := c.service.ListOrders(ctx.Request.Context(), filters, page, limit)
+	orders, err := c.service.ListOrders(ctx.Request.Context(), filter, opts)
 	if err != nil {
-		c.logger.Error("Failed to list orders",
+		requestLogger(ctx, c.logger).Error("Failed to list orders",
 			zap.Error(err),
-			zap.Any("filters", filters),
-			zap.Int("page", page),
-			zap.Int("limit", limit),
+			zap.Any("filter", filter),
+			zap.Int("page", opts.Page),
+			zap.Int("limit", opts.Limit),
 		)
+		_ = ctx.Error(err)
+		return
+	}
 
-		if err == errors.ErrInvalidStatus {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status value"})
-			return
-		}
+	setPageLinks(ctx, orders, opts)
+
+	ctx.JSON(http.StatusOK, orders)
+}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list orders"})
+// SearchOrders handles searching orders by partial order ID, SKU, customer name or address
+// @Summary Search orders
+// @Description Full-text search over orders, ranked by relevance, with the matched fields highlighted
+// @Tags orders
+// @Produce json
+// @Param q query string true "Search query"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {object} models.SearchOrdersResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Deprecated
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/search [get]
+func (c *OrderController) SearchOrders(ctx *gin.Context) {
+	var req models.SearchOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil || req.Limit < 1 || req.Limit > 100 {
+		requestLogger(ctx, c.logger).Error("Invalid search parameters", zap.Error(err))
+		_ = ctx.Error(errors.ErrInvalidLimit)
 		return
 	}
 
-	if len(orders) == 0 {
-		ctx.JSON(http.StatusOK, []domain.Order{})
+	query := strings.TrimSpace(req.Query)
+	if len(query) < 2 {
+		_ = ctx.Error(errors.ErrInvalidSearchQuery)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	results, err := c.service.SearchOrders(ctx.Request.Context(), query, req.Limit)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to search orders", zap.Error(err), zap.String("query", query))
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, results)
 }
 
 // UpdateOrderStatus handles updating the status of an order
@@ -144,74 +216,83 @@ func (c *OrderController) ListOrders(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
-// @Param input body UpdateOrderStatusRequest true "Status update data"
-// @Success 200 {object} models.UpdateOrderStatusResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /api/v1/orders/{id}/status [put]
+// @Param input body models.UpdateOrderStatusRequest true "Status update data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Deprecated
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/{id}/status [patch]
 func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Order ID is required"})
+		_ = ctx.Error(errors.ErrInvalidOrderID)
 		return
 	}
 
 	var req *models.UpdateOrderStatusRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		c.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
 		return
 	}
 
 	// Validate status
 	if !domain.IsValidStatus(domain.OrderStatus(strings.ToUpper(req.Status))) {
-		c.logger.Error("Invalid status value",
+		requestLogger(ctx, c.logger).Error("Invalid status value",
 			zap.String("status", req.Status),
 		)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.ErrInvalidStatus.Error()})
+		_ = ctx.Error(errors.ErrInvalidStatus)
 		return
 	}
 
 	err := c.service.UpdateOrderStatus(ctx.Request.Context(), id, domain.OrderStatus(req.Status))
 	if err != nil {
-		c.logger.Error("Failed to update order status",
+		requestLogger(ctx, c.logger).Error("Failed to update order status",
 			zap.Error(err),
 			zap.String("order_id", id),
 			zap.String("status", string(req.Status)),
 		)
-
-		if err == errors.ErrOrderNotFound {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": errors.ErrOrderNotFound.Error()})
-			return
-		}
-
-		if err == errors.ErrInvalidTransition {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.ErrInvalidTransition.Error()})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrFailedToUpdateOrder.Error()})
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToUpdateOrder))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Order status updated successfully"})
 }
 
-// validateOrder validates the order
-func (c *OrderController) validateOrder(order *models.CreateOrderRequest) error {
-	if order == nil {
-		return errors.ErrInvalidOrder
+// apiErrorOr keeps the API errors, such as a missing order, and replaces any other error by
+// the fallback so internal details are not exposed
+func apiErrorOr(err error, fallback errors.Error) error {
+	var apiErr errors.Error
+	if stderrors.As(err, &apiErr) {
+		return err
 	}
+	return fallback
+}
+
+// requestLogger returns logger with the ID of the request, so the logs of the controllers,
+// services and repositories handling it can be correlated
+func requestLogger(ctx *gin.Context, logger *zap.Logger) *zap.Logger {
+	return requestid.Logger(ctx.Request.Context(), logger)
+}
 
+// validateOrder validates the order, reporting the failures like the binding rules
+func (c *OrderController) validateOrder(order *models.CreateOrderRequest) error {
 	if len(order.Items) == 0 {
-		return errors.ErrItemsIsRequired
+		return errors.NewValidationError("request validation failed", []errors.ValidationError{
+			{Field: "items", Message: "is required"},
+		})
 	}
 
 	return nil
 }
 
-func validatePaginationParams(ctx *gin.Context) (int, int, error) {
+func validatePaginationParams(ctx *gin.Context) (domain.ListOptions, error) {
 	// Parse pagination parameters with defaults
 	pageStr := ctx.DefaultQuery("page", "1")
 	limitStr := ctx.DefaultQuery("limit", "10")
@@ -219,13 +300,131 @@ func validatePaginationParams(ctx *gin.Context) (int, int, error) {
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
 
-		return 0, 0, errors.ErrInvalidPage
+		return domain.ListOptions{}, errors.ErrInvalidPage
 	}
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 1 || limit > 100 {
-		return 0, 0, errors.ErrInvalidLimit
+		return domain.ListOptions{}, errors.ErrInvalidLimit
+	}
+
+	opts := domain.ListOptions{Page: page, Limit: limit}
+
+	// Counting can be skipped on large collections
+	if includeTotal, ok := ctx.GetQuery("include_total"); ok {
+		include, err := strconv.ParseBool(includeTotal)
+		if err != nil {
+			return domain.ListOptions{}, errors.ErrInvalidRequest
+		}
+		opts.SkipTotal = !include
 	}
 
-	return page, limit, nil
+	// An empty cursor asks for the first page in cursor mode
+	if cursorStr, ok := ctx.GetQuery("cursor"); ok {
+		opts.CursorMode = true
+		if cursorStr != "" {
+			cursor, err := domain.DecodeCursor(cursorStr)
+			if err != nil {
+				return domain.ListOptions{}, errors.ErrInvalidCursor
+			}
+			opts.Cursor = cursor
+		}
+	}
+
+	return opts, nil
+}
+
+// parseListRequest parses the filters, pagination, sort and read options of a listing
+func parseListRequest(ctx *gin.Context) (domain.OrderFilter, domain.ListOptions, error) {
+	opts, err := validatePaginationParams(ctx)
+	if err != nil {
+		return domain.OrderFilter{}, domain.ListOptions{}, err
+	}
+
+	var req models.ListOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return domain.OrderFilter{}, domain.ListOptions{}, validation.NewError(err)
+	}
+
+	filter, err := req.ToFilter()
+	if err != nil {
+		return domain.OrderFilter{}, domain.ListOptions{}, err
+	}
+
+	if opts.Read, err = parseReadOptions(ctx); err != nil {
+		return domain.OrderFilter{}, domain.ListOptions{}, err
+	}
+
+	opts.Sort, err = req.ToSort()
+	if err == nil && opts.CursorMode && !opts.IsDefaultSort() {
+		err = errors.ErrCursorSort
+	}
+	if err != nil {
+		return domain.OrderFilter{}, domain.ListOptions{}, err
+	}
+
+	return filter, opts, nil
+}
+
+// parseReadOptions parses the sparse fieldset (fields=order_id,status) and the related
+// resources to inline (expand=history)
+func parseReadOptions(ctx *gin.Context) (domain.ReadOptions, error) {
+	var read domain.ReadOptions
+
+	for _, field := range splitList(ctx.Query("fields")) {
+		if !domain.IsSelectableField(field) {
+			return domain.ReadOptions{}, errors.ErrInvalidFields
+		}
+		read.Fields = append(read.Fields, field)
+	}
+
+	for _, resource := range splitList(ctx.Query("expand")) {
+		if !domain.IsExpandable(resource) {
+			return domain.ReadOptions{}, errors.ErrInvalidExpand
+		}
+		read.Expand = append(read.Expand, resource)
+	}
+
+	return read, nil
+}
+
+// splitList splits a comma separated query parameter, skipping empty values
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// setPageLinks sets the links to the next and previous pages, keeping the rest of the query
+func setPageLinks(ctx *gin.Context, orders *models.ListOrdersResponse, opts domain.ListOptions) {
+	if opts.CursorMode {
+		if orders.NextCursor != "" {
+			orders.Next = pageLink(ctx, "cursor", orders.NextCursor)
+		}
+		if orders.PrevCursor != "" {
+			orders.Prev = pageLink(ctx, "cursor", orders.PrevCursor)
+		}
+		return
+	}
+
+	if orders.HasMore {
+		orders.Next = pageLink(ctx, "page", strconv.Itoa(opts.Page+1))
+	}
+	if opts.Page > 1 {
+		orders.Prev = pageLink(ctx, "page", strconv.Itoa(opts.Page-1))
+	}
+}
+
+// pageLink returns the request URL with the given query parameter replaced
+func pageLink(ctx *gin.Context, key, value string) string {
+	query := ctx.Request.URL.Query()
+	query.Set(key, value)
+
+	link := *ctx.Request.URL
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
 }
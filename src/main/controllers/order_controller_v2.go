@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"net/http"
+	modelsv2 "order-management-ms/src/main/models/api/v2"
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/validation"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateOrder handles the creation of a new order
+// @Summary Create a new order
+// @Description Creates a new order with the provided items
+// @Tags orders-v2
+// @Accept json
+// @Produce json
+// @Param input body modelsv2.CreateOrderRequest true "Order data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 201 {object} modelsv2.OrderResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders [post]
+func (c *OrderControllerV2) CreateOrder(ctx *gin.Context) {
+	var req modelsv2.CreateOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
+		return
+	}
+
+	order, err := c.service.CreateOrder(ctx.Request.Context(), req.ToDomain())
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to create order", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToCreateOrder))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, modelsv2.NewOrderResponse(order, nil))
+}
+
+// GetOrder handles retrieving an order by ID
+// @Summary Get an order by ID
+// @Description Retrieves details of a specific order
+// @Tags orders-v2
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param fields query string false "Comma separated fields to return, e.g. order_id,status"
+// @Param expand query string false "Comma separated related resources to inline: history"
+// @Param If-None-Match header string false "ETag of the version held by the client"
+// @Param If-Modified-Since header string false "Last-Modified of the version held by the client"
+// @Success 200 {object} modelsv2.OrderResponse
+// @Success 304 "Not modified since the version of If-None-Match or If-Modified-Since"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders/{id} [get]
+func (c *OrderControllerV2) GetOrder(ctx *gin.Context) {
+	orderID := ctx.Param("id")
+
+	read, err := parseReadOptions(ctx)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid read options", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	order, err := c.service.GetOrder(ctx.Request.Context(), orderID, read)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to get order", zap.Error(err), zap.String("order_id", orderID))
+		_ = ctx.Error(err)
+		return
+	}
+	if notModified(ctx, order.ETag(), order.UpdatedAt) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, modelsv2.NewOrderResponse(order, read.Fields))
+}
+
+// ListOrders handles listing orders
+// @Summary List orders
+// @Description Lists the orders matching the filters in a data, meta and links envelope, using page pagination or, when cursor is set, cursor pagination
+// @Tags orders-v2
+// @Produce json
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param status query string false "Comma separated order statuses, e.g. NEW,IN_PROGRESS"
+// @Param customer_id query string false "Customer ID"
+// @Param sku query string false "Only orders containing this SKU"
+// @Param created_from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_from query string false "Updated at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_to query string false "Updated at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param min_total query number false "Minimum order total"
+// @Param max_total query number false "Maximum order total"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10)
+// @Param cursor query string false "Cursor returned as meta.next_cursor or meta.prev_cursor, empty for the first page"
+// @Param include_total query bool false "Count the matching orders" default(true)
+// @Param fields query string false "Comma separated fields to return for each order, e.g. order_id,status"
+// @Param expand query string false "Comma separated related resources to inline: history"
+// @Param sort query string false "Comma separated sort keys among created_at, updated_at, status, total and customer_id, prefixed with - for descending order" default(-created_at)
+// @Success 200 {object} modelsv2.ListOrdersResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders [get]
+func (c *OrderControllerV2) ListOrders(ctx *gin.Context) {
+	filter, opts, err := parseListRequest(ctx)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid list parameters", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	orders, err := c.service.ListOrders(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to list orders",
+			zap.Error(err),
+			zap.Any("filter", filter),
+			zap.Int("page", opts.Page),
+			zap.Int("limit", opts.Limit),
+		)
+		_ = ctx.Error(err)
+		return
+	}
+
+	setPageLinks(ctx, orders, opts)
+
+	ctx.JSON(http.StatusOK, modelsv2.NewListOrdersResponse(orders, opts.Read.Fields))
+}
+
+// SearchOrders handles searching orders by partial order ID, SKU, customer name or address
+// @Summary Search orders
+// @Description Full-text search over orders, ranked by relevance, with the matched fields highlighted
+// @Tags orders-v2
+// @Produce json
+// @Param q query string true "Search query"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {object} modelsv2.SearchOrdersResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders/search [get]
+func (c *OrderControllerV2) SearchOrders(ctx *gin.Context) {
+	var req modelsv2.SearchOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil || req.Limit < 1 || req.Limit > 100 {
+		requestLogger(ctx, c.logger).Error("Invalid search parameters", zap.Error(err))
+		_ = ctx.Error(errors.ErrInvalidLimit)
+		return
+	}
+
+	query := strings.TrimSpace(req.Query)
+	if len(query) < 2 {
+		_ = ctx.Error(errors.ErrInvalidSearchQuery)
+		return
+	}
+
+	results, err := c.service.SearchOrders(ctx.Request.Context(), query, req.Limit)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to search orders", zap.Error(err), zap.String("query", query))
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, modelsv2.NewSearchOrdersResponse(results))
+}
+
+// UpdateOrderStatus handles updating the status of an order
+// @Summary Update order status
+// @Description Updates the status of an existing order and returns the updated order
+// @Tags orders-v2
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param input body modelsv2.UpdateOrderStatusRequest true "Status update data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.OrderResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders/{id}/status [patch]
+func (c *OrderControllerV2) UpdateOrderStatus(ctx *gin.Context) {
+	var req modelsv2.UpdateOrderStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
+		return
+	}
+
+	status := domain.OrderStatus(strings.ToUpper(req.Status))
+	if !domain.IsValidStatus(status) {
+		requestLogger(ctx, c.logger).Error("Invalid status value", zap.String("status", req.Status))
+		_ = ctx.Error(errors.ErrInvalidStatus)
+		return
+	}
+
+	c.changeStatus(ctx, ctx.Param("id"), status)
+}
+
+// CancelOrder handles cancelling an order
+// @Summary Cancel an order
+// @Description Cancels an order that has not been delivered yet and returns the cancelled order
+// @Tags orders-v2
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.OrderResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v2/orders/{id}/cancel [post]
+func (c *OrderControllerV2) CancelOrder(ctx *gin.Context) {
+	c.changeStatus(ctx, ctx.Param("id"), domain.StatusCancelled)
+}
+
+// changeStatus moves the order to the given status and writes the updated order
+func (c *OrderControllerV2) changeStatus(ctx *gin.Context, orderID string, status domain.OrderStatus) {
+	if err := c.service.UpdateOrderStatus(ctx.Request.Context(), orderID, status); err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to update order status",
+			zap.Error(err),
+			zap.String("order_id", orderID),
+			zap.String("status", string(status)),
+		)
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToUpdateOrder))
+		return
+	}
+
+	order, err := c.service.GetOrder(ctx.Request.Context(), orderID, domain.ReadOptions{})
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to get order", zap.Error(err), zap.String("order_id", orderID))
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, modelsv2.NewOrderResponse(order, nil))
+}
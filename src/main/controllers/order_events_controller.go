@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StreamOrderEvents handles streaming the status changes of the orders
+// @Summary Stream order status changes
+// @Description Streams the status changes of the orders as Server-Sent Events of type order.status_changed, with a heartbeat comment while idle. Clients resume after the last event they received by sending its ID in Last-Event-ID.
+// @Tags orders
+// @Produce text/event-stream
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param customer_id query string false "Customer ID"
+// @Param status query string false "Comma separated new statuses, e.g. IN_PROGRESS,DELIVERED"
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Param last_event_id query string false "Same as Last-Event-ID, for clients that cannot set headers"
+// @Success 200 {object} kafka.OrderStatusChangedEvent "Stream of events"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/events [get]
+func (c *OrderEventsController) StreamOrderEvents(ctx *gin.Context) {
+	filter, lastEventID, err := c.parseEventsRequest(ctx)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid events request", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	c.streamEvents(ctx, filter, lastEventID)
+}
+
+// StreamOrderEventsByID handles streaming the status changes of an order
+// @Summary Stream the status changes of an order
+// @Description Streams the status changes of an order as Server-Sent Events of type order.status_changed, like /api/v1/orders/events
+// @Tags orders
+// @Produce text/event-stream
+// @Param id path string true "Order ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param status query string false "Comma separated new statuses, e.g. IN_PROGRESS,DELIVERED"
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Param last_event_id query string false "Same as Last-Event-ID, for clients that cannot set headers"
+// @Success 200 {object} kafka.OrderStatusChangedEvent "Stream of events"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/{id}/events [get]
+func (c *OrderEventsController) StreamOrderEventsByID(ctx *gin.Context) {
+	orderID := ctx.Param("id")
+
+	filter, lastEventID, err := c.parseEventsRequest(ctx)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid events request", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	filter.OrderIDs = []string{orderID}
+
+	// Only the orders the caller can read are streamed
+	read := domain.ReadOptions{Fields: []string{"order_id"}}
+	if _, err := c.service.GetOrder(ctx.Request.Context(), orderID, read); err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to get order", zap.Error(err), zap.String("order_id", orderID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	c.streamEvents(ctx, filter, lastEventID)
+}
+
+// Shutdown ends the open event streams, which would otherwise keep the graceful shutdown of
+// the server waiting
+func (c *OrderEventsController) Shutdown() {
+	c.stream.Close()
+}
+
+// parseEventsRequest builds the filter of the events of a request, limited to the tenant and
+// to the customer of callers limited to their own orders, and returns the ID to resume after
+func (c *OrderEventsController) parseEventsRequest(ctx *gin.Context) (events.Filter, string, error) {
+	reqCtx := ctx.Request.Context()
+	customerID, err := authz.ReadScope(reqCtx)
+	if err != nil {
+		return events.Filter{}, "", err
+	}
+
+	req := models.ListOrdersRequest{CustomerID: ctx.Query("customer_id"), Status: ctx.Query("status")}
+	orderFilter, err := req.ToFilter()
+	if err != nil {
+		return events.Filter{}, "", err
+	}
+	if customerID != "" {
+		orderFilter.CustomerID = customerID
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+	if lastEventID != "" && !events.IsValidID(lastEventID) {
+		return events.Filter{}, "", errors.ErrInvalidEventID
+	}
+
+	tenantID, _ := tenant.FromContext(reqCtx)
+	return events.Filter{
+		TenantID:   tenantID,
+		CustomerID: orderFilter.CustomerID,
+		Statuses:   orderFilter.Statuses,
+	}, lastEventID, nil
+}
+
+// streamEvents sends the events missed since lastEventID, a page of at most the replay limit at
+// a time, then the live ones, until the client goes away or the stream closes the subscription
+func (c *OrderEventsController) streamEvents(ctx *gin.Context, filter events.Filter, lastEventID string) {
+	reqCtx := ctx.Request.Context()
+
+	// Subscribing before replaying misses no event; the live ones already replayed are skipped
+	sub := c.stream.Subscribe(filter)
+	defer c.stream.Unsubscribe(sub)
+
+	var missed []events.Event
+	if lastEventID != "" {
+		var err error
+		missed, err = c.stream.Replay(reqCtx, filter, lastEventID, c.cfg.ReplayLimit)
+		if err != nil {
+			requestLogger(ctx, c.logger).Error("Failed to replay events", zap.Error(err), zap.String("last_event_id", lastEventID))
+			_ = ctx.Error(errors.ErrInternalServer)
+			return
+		}
+	}
+
+	// The stream outlives the write timeout of the server
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+
+	for {
+		for _, event := range missed {
+			if err := writeEvent(ctx.Writer, event); err != nil {
+				return
+			}
+			lastEventID = event.ID
+		}
+		ctx.Writer.Flush()
+
+		// A full page may be followed by more missed events, which the live ones do not cover
+		if int64(len(missed)) < c.cfg.ReplayLimit {
+			break
+		}
+		var err error
+		missed, err = c.stream.Replay(reqCtx, filter, lastEventID, c.cfg.ReplayLimit)
+		if err != nil {
+			// The client resumes from the last event it received when it reconnects
+			requestLogger(ctx, c.logger).Error("Failed to replay events", zap.Error(err), zap.String("last_event_id", lastEventID))
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(c.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-reqCtx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if !events.IsAfter(event.ID, lastEventID) {
+				continue
+			}
+			if err := writeEvent(ctx.Writer, event); err != nil {
+				return
+			}
+			lastEventID = event.ID
+		case <-heartbeat.C:
+			if _, err := io.WriteString(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// writeEvent writes an event in the Server-Sent Events format
+func writeEvent(w io.Writer, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, events.StatusChangedType, data)
+	return err
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// exportFlushRows is the number of orders written between two flushes of an export, so
+// clients receive the orders as they are read
+const exportFlushRows = 500
+
+// ExportOrders handles exporting orders
+// @Summary Export orders
+// @Description Streams every order matching the listing filters as CSV or newline-delimited JSON, read from the database as it is sent. fields selects the CSV columns, or the NDJSON fields, in order. Archived orders are only exported with include_archived, after the live ones. The export is gzip-compressed when the request accepts it (Accept-Encoding: gzip).
+// @Tags orders
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Param fields query string false "Comma separated fields to export, e.g. order_id,status,total"
+// @Param expand query string false "Comma separated related resources to inline, NDJSON only: history"
+// @Param include_archived query bool false "Also export the archived orders, after the live ones" default(false)
+// @Param status query string false "Comma separated order statuses, e.g. NEW,IN_PROGRESS"
+// @Param customer_id query string false "Customer ID"
+// @Param sku query string false "Only orders containing this SKU"
+// @Param created_from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_from query string false "Updated at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_to query string false "Updated at or before (RFC 3339 or YYYY-MM-DD)"
+// @Param min_total query number false "Minimum order total"
+// @Param max_total query number false "Maximum order total"
+// @Param sort query string false "Comma separated sort keys among created_at, updated_at, status, total and customer_id, prefixed with - for descending order" default(-created_at)
+// @Success 200 {string} string "Orders, one per line"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/export [get]
+func (c *OrderController) ExportOrders(ctx *gin.Context) {
+	var req models.ExportOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		_ = ctx.Error(validation.NewError(err))
+		return
+	}
+	if req.Format != models.ExportCSV && req.Format != models.ExportNDJSON {
+		_ = ctx.Error(errors.ErrInvalidFormat)
+		return
+	}
+
+	filter, err := req.ToFilter()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	filter.IncludeArchived = req.IncludeArchived
+	sort, err := req.ToSort()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	read, err := parseReadOptions(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if req.Format == models.ExportCSV && len(read.Expand) > 0 {
+		_ = ctx.Error(errors.ErrInvalidExpand)
+		return
+	}
+
+	export := &orderExport{ctx: ctx, format: req.Format, columns: read.Fields}
+	if len(export.columns) == 0 {
+		export.columns = models.ExportColumns
+	}
+
+	err = c.service.ExportOrders(ctx.Request.Context(), filter, sort, read, export.write)
+	if err == nil {
+		err = export.close()
+	}
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to export orders", zap.Error(err), zap.Int("rows", export.rows))
+		if !export.started {
+			_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+			return
+		}
+		export.abort()
+	}
+}
+
+// orderExport writes the orders of an export to the response. The response only starts with
+// the first order, or when the export ends, so errors found before can still be answered
+// with a problem.
+type orderExport struct {
+	ctx     *gin.Context
+	format  string
+	columns []string
+
+	started bool
+	rows    int
+	gzip    *gzip.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+// start sends the headers of the export, and the header row of a CSV export
+func (e *orderExport) start() error {
+	e.started = true
+
+	// Large exports outlast the write timeout of the server
+	_ = http.NewResponseController(e.ctx.Writer).SetWriteDeadline(time.Time{})
+
+	header := e.ctx.Writer.Header()
+	filename := "orders-" + time.Now().UTC().Format("2006-01-02") + "." + e.format
+	header.Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	header.Add("Vary", "Accept-Encoding")
+
+	var out io.Writer = e.ctx.Writer
+	if acceptsGzip(e.ctx.Request) {
+		header.Set("Content-Encoding", "gzip")
+		e.gzip = gzip.NewWriter(e.ctx.Writer)
+		out = e.gzip
+	}
+
+	if e.format == models.ExportNDJSON {
+		header.Set("Content-Type", "application/x-ndjson")
+		e.json = json.NewEncoder(out)
+		return nil
+	}
+	header.Set("Content-Type", "text/csv; charset=utf-8")
+	e.csv = csv.NewWriter(out)
+	return e.csv.Write(e.columns)
+}
+
+// write writes an order as a CSV record or a JSON line
+func (e *orderExport) write(order *models.OrderResponse) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if e.json != nil {
+		if err := e.json.Encode(order); err != nil {
+			return err
+		}
+	} else {
+		record, err := order.CSVRecord(e.columns)
+		if err != nil {
+			return err
+		}
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// flush sends the buffered orders to the client
+func (e *orderExport) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if e.gzip != nil {
+		if err := e.gzip.Flush(); err != nil {
+			return err
+		}
+	}
+	e.ctx.Writer.Flush()
+	return nil
+}
+
+// close ends a complete export, starting it first when there was no order to export
+func (e *orderExport) close() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if e.gzip != nil {
+		return e.gzip.Close()
+	}
+	return nil
+}
+
+// abort cuts the connection of an export that failed midway, so the client gets an
+// incomplete response instead of a file that looks complete
+func (e *orderExport) abort() {
+	conn, _, err := http.NewResponseController(e.ctx.Writer).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+// acceptsGzip checks if the request accepts gzip-encoded responses
+func acceptsGzip(req *http.Request) bool {
+	for _, value := range req.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+			if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+				q := strings.ReplaceAll(params, " ", "")
+				return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/auth"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// socketWriteWait bounds the time to write a message to a client
+	socketWriteWait = 10 * time.Second
+	// socketMaxMessageSize bounds the size of the messages read from a client
+	socketMaxMessageSize = 16 << 10
+	// socketOutboxSize is the number of replies queued for the writer of a connection; a
+	// client sending requests faster is no longer read until they are written
+	socketOutboxSize = 16
+)
+
+// upgrader rejects the browser connections of other origins
+var upgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 4096}
+
+// ServeOrderSocket handles the WebSocket of the order updates
+// @Summary Subscribe to order updates over a WebSocket
+// @Description Upgrades to a WebSocket over which the client sends JSON requests: subscribe (order_ids, optional last_event_id to resume), unsubscribe (order_ids) and deliver (order_id). Each request is answered by an ack or an error with its id, and the status changes of the subscribed orders are pushed as event messages. The server pings every heartbeat and closes connections that stop answering, that fall behind (code 1013) or whose token expires (code 1008).
+// @Tags orders
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 101 "Switching Protocols"
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/orders/ws [get]
+func (c *OrderEventsController) ServeOrderSocket(ctx *gin.Context) {
+	reqCtx := ctx.Request.Context()
+	customerID, err := authz.ReadScope(reqCtx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	tenantID, _ := tenant.FromContext(reqCtx)
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// The upgrader already answered the request
+		requestLogger(ctx, c.logger).Warn("Failed to upgrade to a WebSocket", zap.Error(err))
+		return
+	}
+
+	socket := &orderSocket{
+		ctrl:       c,
+		conn:       conn,
+		ctx:        reqCtx,
+		logger:     requestLogger(ctx, c.logger),
+		filter:     events.Filter{TenantID: tenantID, CustomerID: customerID},
+		outbox:     make(chan func() error, socketOutboxSize),
+		readerDone: make(chan struct{}),
+		writerDone: make(chan struct{}),
+		orders:     map[string]string{},
+	}
+	socket.run()
+}
+
+// orderSocket is a WebSocket connection subscribed to order updates. Its reader handles the
+// requests of the client and queues their replies for its writer, the only goroutine writing
+// to the connection and tracking the subscribed orders.
+type orderSocket struct {
+	ctrl   *OrderEventsController
+	conn   *websocket.Conn
+	ctx    context.Context
+	logger *zap.Logger
+	filter events.Filter
+	// sub receives the events of the subscribed orders; it is nil while there are none and
+	// only the writer uses it
+	sub *events.Subscription
+
+	outbox     chan func() error
+	readerDone chan struct{}
+	writerDone chan struct{}
+
+	// orders maps the subscribed orders to the ID of the last event sent for them; only the
+	// writer uses it
+	orders map[string]string
+}
+
+// run serves the connection until the client or the server closes it
+func (s *orderSocket) run() {
+	go s.writeLoop()
+	s.readLoop()
+	close(s.readerDone)
+	<-s.writerDone
+}
+
+// readLoop reads the requests of the client until the connection fails or is closed
+func (s *orderSocket) readLoop() {
+	timeout := 2 * s.ctrl.cfg.Heartbeat
+	s.conn.SetReadLimit(socketMaxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(timeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger.Debug("WebSocket closed", zap.Error(err))
+			}
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(timeout))
+
+		var req models.SocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			s.reply(req.ID, errors.ErrInvalidRequest)
+			continue
+		}
+		s.handle(req)
+	}
+}
+
+// handle answers a request of the client
+func (s *orderSocket) handle(req models.SocketRequest) {
+	switch req.Type {
+	case models.SocketSubscribe:
+		if len(req.OrderIDs) == 0 {
+			s.reply(req.ID, errors.ErrInvalidOrderID)
+			return
+		}
+		if len(req.OrderIDs) > s.ctrl.cfg.MaxSubscriptions {
+			s.reply(req.ID, errors.ErrTooManyOrders)
+			return
+		}
+		if req.LastEventID != "" && !events.IsValidID(req.LastEventID) {
+			s.reply(req.ID, errors.ErrInvalidEventID)
+			return
+		}
+		// Only the orders the caller can read are subscribed
+		read := domain.ReadOptions{Fields: []string{"order_id"}}
+		for _, orderID := range req.OrderIDs {
+			if _, err := s.ctrl.service.GetOrder(s.ctx, orderID, read); err != nil {
+				s.logger.Warn("Failed to subscribe to order", zap.Error(err), zap.String("order_id", orderID))
+				s.reply(req.ID, err)
+				return
+			}
+		}
+		s.enqueue(func() error { return s.subscribe(req) })
+
+	case models.SocketUnsubscribe:
+		s.enqueue(func() error {
+			for _, orderID := range req.OrderIDs {
+				delete(s.orders, orderID)
+			}
+			s.follow()
+			return s.write(models.SocketMessage{Type: models.SocketAck, ID: req.ID})
+		})
+
+	case models.SocketDeliver:
+		if req.OrderID == "" {
+			s.reply(req.ID, errors.ErrInvalidOrderID)
+			return
+		}
+		err := s.ctrl.service.UpdateOrderStatus(s.ctx, req.OrderID, domain.StatusDelivered)
+		if err != nil {
+			s.logger.Warn("Failed to deliver order", zap.Error(err), zap.String("order_id", req.OrderID))
+			err = apiErrorOr(err, errors.ErrFailedToUpdateOrder)
+		}
+		s.reply(req.ID, err)
+
+	default:
+		s.reply(req.ID, errors.ErrInvalidRequest)
+	}
+}
+
+// reply queues the acknowledgement of a request, or its error when err is set. Errors other
+// than the API ones are not exposed.
+func (s *orderSocket) reply(id string, err error) {
+	msg := models.SocketMessage{Type: models.SocketAck, ID: id}
+	if err != nil {
+		var apiErr errors.Error
+		if !stderrors.As(err, &apiErr) {
+			apiErr = errors.ErrInternalServer
+		}
+		msg = models.SocketMessage{Type: models.SocketError, ID: id, Code: apiErr.ErrorCode(), Message: apiErr.Error()}
+	}
+	s.enqueue(func() error { return s.write(msg) })
+}
+
+// enqueue queues a task for the writer, waiting while the outbox is full unless the writer
+// has stopped
+func (s *orderSocket) enqueue(task func() error) {
+	select {
+	case s.outbox <- task:
+	case <-s.writerDone:
+	}
+}
+
+// writeLoop writes the replies, the events of the subscribed orders and the pings until the
+// reader stops or a write fails, then closes the connection
+func (s *orderSocket) writeLoop() {
+	defer close(s.writerDone)
+	defer s.conn.Close()
+	defer func() {
+		if s.sub != nil {
+			s.ctrl.stream.Unsubscribe(s.sub)
+		}
+	}()
+
+	ping := time.NewTicker(s.ctrl.cfg.Heartbeat)
+	defer ping.Stop()
+
+	// Connections authenticated with a token end with it
+	var expired <-chan time.Time
+	if claims, ok := auth.FromContext(s.ctx); ok && claims.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-s.readerDone:
+			return
+		case task := <-s.outbox:
+			if err := task(); err != nil {
+				return
+			}
+		case event, ok := <-s.live():
+			if !ok {
+				// The client fell behind or the server is stopping; it resumes from its last event
+				s.closeWith(websocket.CloseTryAgainLater, "event stream closed, subscribe again")
+				return
+			}
+			if err := s.send(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait)); err != nil {
+				return
+			}
+		case <-expired:
+			s.closeWith(websocket.ClosePolicyViolation, "authorization expired")
+			return
+		}
+	}
+}
+
+// subscribe adds orders to the subscriptions and sends all their events missed since the last
+// event ID of the request, then acknowledges it
+func (s *orderSocket) subscribe(req models.SocketRequest) error {
+	added := 0
+	for _, orderID := range req.OrderIDs {
+		if _, ok := s.orders[orderID]; !ok {
+			added++
+		}
+	}
+	if len(s.orders)+added > s.ctrl.cfg.MaxSubscriptions {
+		return s.write(models.SocketMessage{Type: models.SocketError, ID: req.ID, Code: errors.ErrTooManyOrders.ErrorCode(), Message: errors.ErrTooManyOrders.Error()})
+	}
+	for _, orderID := range req.OrderIDs {
+		if _, ok := s.orders[orderID]; !ok {
+			s.orders[orderID] = ""
+		}
+	}
+	// The live events are followed before the replay, send skips the ones it returns
+	s.follow()
+
+	if req.LastEventID != "" {
+		filter := s.filter
+		filter.OrderIDs = req.OrderIDs
+		// The missed events are read a page at a time until the last page comes back short
+		for afterID := req.LastEventID; ; {
+			missed, err := s.ctrl.stream.Replay(s.ctx, filter, afterID, s.ctrl.cfg.ReplayLimit)
+			if err != nil {
+				s.logger.Error("Failed to replay events", zap.Error(err), zap.String("last_event_id", afterID))
+				return s.write(models.SocketMessage{Type: models.SocketError, ID: req.ID, Code: errors.ErrInternalServer.ErrorCode(), Message: errors.ErrInternalServer.Error()})
+			}
+			for _, event := range missed {
+				if err := s.send(event); err != nil {
+					return err
+				}
+				afterID = event.ID
+			}
+			if int64(len(missed)) < s.ctrl.cfg.ReplayLimit {
+				break
+			}
+		}
+	}
+
+	return s.write(models.SocketMessage{Type: models.SocketAck, ID: req.ID})
+}
+
+// follow subscribes to the events of the subscribed orders only, so the other orders of the
+// tenant do not count against the buffer of the subscription
+func (s *orderSocket) follow() {
+	if len(s.orders) == 0 {
+		// A filter without orders would match all of them
+		if s.sub != nil {
+			s.ctrl.stream.Unsubscribe(s.sub)
+			s.sub = nil
+		}
+		return
+	}
+
+	filter := s.filter
+	filter.OrderIDs = make([]string, 0, len(s.orders))
+	for orderID := range s.orders {
+		filter.OrderIDs = append(filter.OrderIDs, orderID)
+	}
+	if s.sub == nil {
+		s.sub = s.ctrl.stream.Subscribe(filter)
+		return
+	}
+	s.ctrl.stream.SetFilter(s.sub, filter)
+}
+
+// live returns the channel of the events of the subscribed orders, nil while there are none
+func (s *orderSocket) live() <-chan events.Event {
+	if s.sub == nil {
+		return nil
+	}
+	return s.sub.Events()
+}
+
+// send writes an event of a subscribed order, unless it was already sent
+func (s *orderSocket) send(event events.Event) error {
+	lastEventID, ok := s.orders[event.OrderID]
+	if !ok || !events.IsAfter(event.ID, lastEventID) {
+		return nil
+	}
+	s.orders[event.OrderID] = event.ID
+	return s.write(models.SocketMessage{
+		Type:    models.SocketEvent,
+		Event:   events.StatusChangedType,
+		EventID: event.ID,
+		Data:    event,
+	})
+}
+
+// write writes a message to the client
+func (s *orderSocket) write(msg models.SocketMessage) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+	return s.conn.WriteJSON(msg)
+}
+
+// closeWith tells the client why the connection is closed
+func (s *orderSocket) closeWith(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(socketWriteWait))
+}
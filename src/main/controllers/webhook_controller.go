@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+
+	modelsv2 "order-management-ms/src/main/models/api/v2"
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateWebhook handles creating a webhook
+// @Summary Create a webhook
+// @Description Creates a webhook called with the events of the tenant. Deliveries are signed with the secret, generated when none is sent and only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param input body modelsv2.CreateWebhookRequest true "Webhook data"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 201 {object} modelsv2.WebhookResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/webhooks [post]
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	var req modelsv2.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		requestLogger(ctx, c.logger).Error("Invalid request body", zap.Error(err))
+		_ = ctx.Error(validation.NewError(err))
+		return
+	}
+
+	webhook, err := c.service.CreateWebhook(ctx.Request.Context(), req.ToDomain())
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to create webhook", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrFailedToCreateWebhook))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks handles listing the webhooks
+// @Summary List webhooks
+// @Description Lists the webhooks of the tenant, newest first, without their secrets
+// @Tags webhooks
+// @Produce json
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.ListWebhooksResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/webhooks [get]
+func (c *WebhookController) ListWebhooks(ctx *gin.Context) {
+	webhooks, err := c.service.ListWebhooks(ctx.Request.Context())
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to list webhooks", zap.Error(err))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook handles deleting a webhook
+// @Summary Delete a webhook
+// @Description Deletes a webhook. Its pending deliveries are moved to the dead letters.
+// @Tags webhooks
+// @Param id path string true "Webhook ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 204
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/webhooks/{id} [delete]
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	webhookID := ctx.Param("id")
+
+	if err := c.service.DeleteWebhook(ctx.Request.Context(), webhookID); err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to delete webhook", zap.Error(err), zap.String("webhook_id", webhookID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles listing the deliveries of a webhook
+// @Summary List webhook deliveries
+// @Description Lists the latest 100 deliveries of a webhook, newest first, with the log of their attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(PENDING, SUCCEEDED, DEAD_LETTER)
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 200 {object} modelsv2.ListWebhookDeliveriesResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/webhooks/{id}/deliveries [get]
+func (c *WebhookController) ListWebhookDeliveries(ctx *gin.Context) {
+	webhookID := ctx.Param("id")
+	status := domain.DeliveryStatus(ctx.Query("status"))
+	if status != "" && !slices.Contains(domain.DeliveryStatuses, status) {
+		_ = ctx.Error(errors.ErrInvalidDeliveryStatus)
+		return
+	}
+
+	deliveries, err := c.service.ListDeliveries(ctx.Request.Context(), webhookID, status)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to list webhook deliveries", zap.Error(err), zap.String("webhook_id", webhookID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook handles redelivering an event to a webhook
+// @Summary Redeliver a webhook delivery
+// @Description Queues a delivery again, whatever its status, with a new budget of attempts. Its log is kept.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Param X-Tenant-ID header string false "Tenant ID, required unless a default tenant is configured"
+// @Success 202 {object} modelsv2.WebhookDeliveryResponse
+// @Failure 400 {object} customerrors.ProblemDetails
+// @Failure 403 {object} customerrors.ProblemDetails
+// @Failure 404 {object} customerrors.ProblemDetails
+// @Failure 429 {object} customerrors.ProblemDetails
+// @Failure 500 {object} customerrors.ProblemDetails
+// @Security BearerAuth
+// @Router /api/v2/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (c *WebhookController) RedeliverWebhook(ctx *gin.Context) {
+	webhookID := ctx.Param("id")
+	deliveryID := ctx.Param("delivery_id")
+
+	delivery, err := c.service.Redeliver(ctx.Request.Context(), webhookID, deliveryID)
+	if err != nil {
+		requestLogger(ctx, c.logger).Error("Failed to redeliver webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID))
+		_ = ctx.Error(apiErrorOr(err, errors.ErrInternalServer))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, delivery)
+}
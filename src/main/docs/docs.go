@@ -0,0 +1,31 @@
+// Package docs holds the API specification generated by swag from the controller
+// annotations. Run `go generate ./src/main` after changing them.
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed swagger.json
+var swaggerJSON []byte
+
+// OpenAPI returns the specification converted to OpenAPI 3
+func OpenAPI() (*openapi3.T, error) {
+	var spec openapi2.T
+	if err := json.Unmarshal(swaggerJSON, &spec); err != nil {
+		return nil, err
+	}
+
+	doc, err := openapi2conv.ToV3(&spec)
+	if err != nil {
+		return nil, err
+	}
+	doc.OpenAPI = "3.0.3"
+
+	return doc, doc.Validate(openapi3.NewLoader().Context)
+}
@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,16 +13,40 @@ import (
 	"order-management-ms/src/main/config"
 	ordercontroller "order-management-ms/src/main/controllers"
 	"order-management-ms/src/main/pkg/api"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/auth"
+	"order-management-ms/src/main/pkg/authz"
 	"order-management-ms/src/main/pkg/cache"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/grpcapi"
 	"order-management-ms/src/main/pkg/kafka"
 	"order-management-ms/src/main/pkg/mongodb"
+	"order-management-ms/src/main/pkg/ratelimit"
 	mongodbrepo "order-management-ms/src/main/repositories/mongodb"
+	apikeyservice "order-management-ms/src/main/services/apikeys"
+	archiveservice "order-management-ms/src/main/services/archive"
 	orderservice "order-management-ms/src/main/services/orders"
+	webhookservice "order-management-ms/src/main/services/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
+//go:generate swag init --generalInfo main.go --dir ./ --parseInternal --outputTypes json --output ./docs
+
+// @title Order Management API
+// @version 2.0
+// @description Manages the lifecycle of delivery orders. Order routes are scoped to the tenant sent in the X-Tenant-ID header.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token, sent as "Bearer <token>"
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of a partner system, scoped to orders:read, orders:write or orders:status
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -68,18 +93,124 @@ func main() {
 	orderRepo := mongodbrepo.NewOrderRepository(
 		mongoClient.Database(cfg.MongoDB.Database),
 		cfg.MongoDB.Collection,
+		cfg.Archive.Collection,
 		logger,
 	)
+	if err := orderRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Fatal("Failed to create MongoDB indexes", zap.Error(err))
+	}
+	if err := orderRepo.Migrate(context.Background(), cfg.Tenant.Default); err != nil {
+		logger.Fatal("Failed to migrate the orders", zap.Error(err))
+	}
 	cacheRepo := cache.NewRedisRepository(redisClient, logger)
+	apiKeyRepo := mongodbrepo.NewAPIKeyRepository(mongoClient.Database(cfg.MongoDB.Database), cfg.APIKeys.Collection, logger)
+	if cfg.APIKeys.Enabled {
+		if err := apiKeyRepo.EnsureIndexes(context.Background()); err != nil {
+			logger.Fatal("Failed to create MongoDB indexes", zap.Error(err))
+		}
+	}
+
+	webhookRepo := mongodbrepo.NewWebhookRepository(
+		mongoClient.Database(cfg.MongoDB.Database),
+		cfg.Webhooks.Collection,
+		cfg.Webhooks.DeliveriesCollection,
+		cfg.Webhooks.Retention,
+		logger,
+	)
+	if cfg.Webhooks.Enabled {
+		if err := webhookRepo.EnsureIndexes(context.Background()); err != nil {
+			logger.Fatal("Failed to create MongoDB indexes", zap.Error(err))
+		}
+	}
+	webhookService := webhookservice.NewWebhookService(webhookRepo, cfg.Webhooks, http.DefaultClient, logger)
+
+	// Order events are published to the Kafka topic, and to the live event stream and the
+	// webhooks when they are enabled
+	eventPublisher := events.Publishers{kafkaProducer}
+	var eventStream *events.Stream
+	if cfg.Events.Enabled {
+		eventStream = events.NewStream(redisClient, cfg.Events, logger)
+		eventPublisher = append(eventPublisher, eventStream)
+	}
+	if cfg.Webhooks.Enabled {
+		eventPublisher = append(eventPublisher, webhookService)
+	}
 
 	// Initialize services
-	orderService := orderservice.NewOrderService(orderRepo, logger, cacheRepo, kafkaProducer)
+	orderService := orderservice.NewOrderService(orderRepo, orderRepo, logger, cacheRepo, eventPublisher)
+	archiveService := archiveservice.NewArchiveService(orderRepo, cfg.Archive, logger)
+	apiKeyService := apikeyservice.NewAPIKeyService(apiKeyRepo, cfg.APIKeys, logger)
+
+	// Run background jobs until the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go archiveService.Run(jobsCtx)
+	if eventStream != nil {
+		go eventStream.Run(jobsCtx)
+	}
+	go webhookService.Run(jobsCtx)
 
 	// Initialize controllers
 	orderCtrl := ordercontroller.NewOrderController(orderService, logger)
+	orderV2Ctrl := ordercontroller.NewOrderControllerV2(orderService, logger)
+	graphqlCtrl, err := ordercontroller.NewGraphQLController(orderService, logger)
+	if err != nil {
+		logger.Fatal("Failed to build GraphQL schema", zap.Error(err))
+	}
+	var eventsCtrl *ordercontroller.OrderEventsController
+	if eventStream != nil {
+		eventsCtrl = ordercontroller.NewOrderEventsController(orderService, eventStream, cfg.Events, logger)
+	}
+
+	// Initialize authentication and authorization
+	var verifier *auth.Verifier
+	var authenticate gin.HandlerFunc
+	policy := authz.DefaultPolicy()
+	if cfg.Auth.PolicyFile != "" {
+		policy, err = authz.LoadPolicy(cfg.Auth.PolicyFile)
+		if err != nil {
+			logger.Fatal("Failed to load the authorization policy", zap.Error(err))
+		}
+	}
+	if cfg.Auth.Enabled {
+		keys, err := auth.NewKeySet(context.Background(), cfg.Auth.JWKS, cfg.Auth.JWKSRefresh)
+		if err != nil {
+			logger.Fatal("Failed to load the JSON Web Key Set", zap.Error(err))
+		}
+		verifier = auth.NewVerifier(keys, cfg.Auth, cfg.Tenant.Default)
+		authenticate = auth.Middleware(verifier, policy, logger)
+	} else {
+		logger.Warn("Authentication is disabled, the order routes are public")
+	}
+
+	// Partner systems authenticate with API keys, managed by the admins of the bearer tokens
+	var apiKeyCtrl *ordercontroller.APIKeyController
+	var apiKeys apikey.Authenticator
+	if cfg.APIKeys.Enabled {
+		apiKeys = apiKeyService
+		authenticate = apikey.Middleware(cfg.APIKeys.Header, apiKeyService, authenticate, logger)
+		if cfg.Auth.Enabled {
+			apiKeyCtrl = ordercontroller.NewAPIKeyController(apiKeyService, logger)
+		}
+	}
+
+	// Admins manage the webhooks of their tenant
+	var webhookCtrl *ordercontroller.WebhookController
+	if cfg.Webhooks.Enabled && cfg.Auth.Enabled {
+		webhookCtrl = ordercontroller.NewWebhookController(webhookService, logger)
+	}
+
+	// Initialize rate limiting, sharing its counters across instances through Redis
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.NewLimiter(redisClient)
+	}
+
+	// Initialize gRPC server
+	grpcServer := grpcapi.NewServer(cfg, orderService, verifier, apiKeys, policy, logger)
 
 	// Run server
-	runServer(cfg, orderCtrl, logger)
+	runServer(cfg, orderCtrl, orderV2Ctrl, graphqlCtrl, apiKeyCtrl, eventsCtrl, webhookCtrl, authenticate, limiter, grpcServer, logger)
 }
 
 // initGinMode  Initialize gin mode, this function is used to set the gin mode based on the environment variable GIN_MODE
@@ -132,9 +263,9 @@ func initLogger(cfg *config.Config) (*zap.Logger, error) {
 	return logger, nil
 }
 
-func runServer(cfg *config.Config, orderCtrl *ordercontroller.OrderController, logger *zap.Logger) {
+func runServer(cfg *config.Config, orderCtrl *ordercontroller.OrderController, orderV2Ctrl *ordercontroller.OrderControllerV2, graphqlCtrl *ordercontroller.GraphQLController, apiKeyCtrl *ordercontroller.APIKeyController, eventsCtrl *ordercontroller.OrderEventsController, webhookCtrl *ordercontroller.WebhookController, authenticate gin.HandlerFunc, limiter *ratelimit.Limiter, grpcServer *grpc.Server, logger *zap.Logger) {
 	// Configure router
-	router := api.SetupRouter(orderCtrl, logger)
+	router := api.SetupRouter(cfg, orderCtrl, orderV2Ctrl, graphqlCtrl, apiKeyCtrl, eventsCtrl, webhookCtrl, authenticate, limiter, logger)
 
 	// Configure HTTP server
 	srv := &http.Server{
@@ -143,6 +274,9 @@ func runServer(cfg *config.Config, orderCtrl *ordercontroller.OrderController, l
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	if eventsCtrl != nil {
+		srv.RegisterOnShutdown(eventsCtrl.Shutdown)
+	}
 
 	// Run the server in a goroutine
 	go func() {
@@ -152,6 +286,20 @@ func runServer(cfg *config.Config, orderCtrl *ordercontroller.OrderController, l
 		}
 	}()
 
+	// Run the gRPC server on its own port
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			logger.Fatal("Failed to listen on gRPC port", zap.Error(err))
+		}
+		go func() {
+			logger.Info("Starting gRPC server", zap.String("port", cfg.GRPC.Port))
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Fatal("Failed to start gRPC server", zap.Error(err))
+			}
+		}()
+	}
+
 	// Wait for signal to stop
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -163,9 +311,23 @@ func runServer(cfg *config.Config, orderCtrl *ordercontroller.OrderController, l
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GracefulShutdown)
 	defer cancel()
 
+	// Open streams keep a graceful stop waiting, so they are cut when the timeout expires
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		logger.Warn("gRPC server forced to stop")
+		grpcServer.Stop()
+	}
+
 	logger.Info("Server exiting")
 }
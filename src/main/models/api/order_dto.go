@@ -1,37 +1,142 @@
 package api
 
 import (
+	"encoding/json"
+	"strconv"
 	"time"
 )
 
 // CreateOrderRequest represents the request body for creating an order
 type CreateOrderRequest struct {
-	CustomerID string  `json:"customer_id" binding:"required"`
-	Items      []Items `json:"items" binding:"required,min=1"`
+	CustomerID      string  `json:"customer_id" binding:"required,customer_id" maxLength:"64"`
+	CustomerName    string  `json:"customer_name,omitempty"`
+	ShippingAddress string  `json:"shipping_address,omitempty"`
+	Items           []Items `json:"items" binding:"required,min=1,dive"`
 }
 
 type Items struct {
-	Sku      string  `json:"sku" binding:"required"`
-	Quantity int     `json:"quantity" binding:"required"`
-	Price    float64 `json:"price,omitempty"`
+	Sku      string  `json:"sku" binding:"required,sku" maxLength:"64"`
+	Quantity int     `json:"quantity" binding:"gt=0" minimum:"1"`
+	Price    float64 `json:"price,omitempty" binding:"gte=0" minimum:"0"`
 }
 
 // ListOrdersRequest represents the query parameters for listing orders
 type ListOrdersRequest struct {
-	Status     string `form:"status"`
-	CustomerID string `form:"customer_id"`
-	Page       int    `form:"page,default=1"`
-	Limit      int    `form:"limit,default=10"`
+	Status      string `form:"status"`
+	CustomerID  string `form:"customer_id"`
+	SKU         string `form:"sku"`
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+	UpdatedFrom string `form:"updated_from"`
+	UpdatedTo   string `form:"updated_to"`
+	MinTotal    string `form:"min_total"`
+	MaxTotal    string `form:"max_total"`
+	Sort        string `form:"sort"`
+	Page        int    `form:"page,default=1"`
+	Limit       int    `form:"limit,default=10"`
 }
 
 // OrderResponse represents the response body for an order
 type OrderResponse struct {
-	CustomerID string    `json:"customer_id"`
-	OrderID    string    `json:"order_id"`
-	Status     string    `json:"status"`
-	Items      []Items   `json:"items"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	CustomerID      string     `json:"customer_id"`
+	CustomerName    string     `json:"customer_name,omitempty"`
+	ShippingAddress string     `json:"shipping_address,omitempty"`
+	OrderID         string     `json:"order_id"`
+	Status          string     `json:"status"`
+	Items           []Items    `json:"items"`
+	Total           float64    `json:"total"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+
+	// Expanded related resources
+	History []StatusChangeResponse `json:"history,omitempty"`
+
+	// fields limits the JSON encoding to a sparse fieldset, every field when empty
+	fields []string
+}
+
+// StatusChangeResponse represents an entry of the status history of an order
+type StatusChangeResponse struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// MarshalJSON encodes the order, keeping only the selected fields and the expanded
+// resources when the response is sparse
+func (r OrderResponse) MarshalJSON() ([]byte, error) {
+	type plainOrderResponse OrderResponse
+	encoded, err := json.Marshal(plainOrderResponse(r))
+	if err != nil || len(r.fields) == 0 {
+		return encoded, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+
+	sparse := make(map[string]json.RawMessage, len(r.fields)+1)
+	for _, field := range append(r.fields, "history") {
+		if value, ok := all[field]; ok {
+			sparse[field] = value
+		}
+	}
+	return json.Marshal(sparse)
+}
+
+// ETag returns the weak entity tag of the order version, which changes whenever the order is
+// updated or archived. It is weak as the sparse and versioned representations of a version
+// share it.
+func (r *OrderResponse) ETag() string {
+	version := strconv.FormatInt(r.UpdatedAt.UnixMilli(), 36)
+	if r.ArchivedAt != nil {
+		version += "-" + strconv.FormatInt(r.ArchivedAt.UnixMilli(), 36)
+	}
+	return `W/"` + version + `"`
+}
+
+// ListOrdersResponse represents a page of orders with its pagination metadata. Page is only
+// set for page pagination and the cursors only for cursor pagination; Total is omitted when
+// the count was skipped.
+type ListOrdersResponse struct {
+	Data       []*OrderResponse `json:"data"`
+	Page       int              `json:"page,omitempty"`
+	Limit      int              `json:"limit"`
+	Total      *int64           `json:"total,omitempty"`
+	HasMore    bool             `json:"has_more"`
+	Next       string           `json:"next,omitempty"`
+	Prev       string           `json:"prev,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	PrevCursor string           `json:"prev_cursor,omitempty"`
+}
+
+// SearchOrdersRequest represents the query parameters for searching orders
+type SearchOrdersRequest struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit,default=20"`
+}
+
+// SearchOrdersResponse represents the orders matching a search, most relevant first
+type SearchOrdersResponse struct {
+	Query string               `json:"query"`
+	Data  []*SearchHitResponse `json:"data"`
+}
+
+// SearchHitResponse represents an order matching a search, with its relevance score and the
+// matching fields highlighted with <em> tags
+type SearchHitResponse struct {
+	Order      *OrderResponse      `json:"order"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights"`
+}
+
+// CustomerSummaryResponse represents the order statistics of a customer
+type CustomerSummaryResponse struct {
+	CustomerID  string    `json:"customer_id"`
+	OrderCount  int64     `json:"order_count"`
+	TotalSpent  float64   `json:"total_spent"`
+	LastOrderAt time.Time `json:"last_order_at"`
 }
 
 // UpdateOrderStatusRequest represents the request body for updating order status
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the order exports
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+)
+
+// ExportColumns are the columns of the CSV exports when no fields are selected, in order
+var ExportColumns = []string{
+	"order_id", "customer_id", "customer_name", "shipping_address", "status",
+	"items", "total", "created_at", "updated_at", "archived_at",
+}
+
+// ExportOrdersRequest represents the query parameters for exporting orders: the filters and
+// sort of the listings, and the format of the export
+type ExportOrdersRequest struct {
+	ListOrdersRequest
+	Format          string `form:"format,default=csv"`
+	IncludeArchived bool   `form:"include_archived"`
+}
+
+// CSVRecord returns the values of the columns of the order for a CSV export. Items are
+// encoded as JSON, times in RFC 3339, and missing values are left empty. Values sent by the
+// customers are escaped so spreadsheets do not evaluate them as formulas.
+func (r *OrderResponse) CSVRecord(columns []string) ([]string, error) {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "order_id":
+			record[i] = r.OrderID
+		case "customer_id":
+			record[i] = escapeFormula(r.CustomerID)
+		case "customer_name":
+			record[i] = escapeFormula(r.CustomerName)
+		case "shipping_address":
+			record[i] = escapeFormula(r.ShippingAddress)
+		case "status":
+			record[i] = r.Status
+		case "items":
+			items, err := json.Marshal(r.Items)
+			if err != nil {
+				return nil, err
+			}
+			record[i] = escapeFormula(string(items))
+		case "total":
+			record[i] = strconv.FormatFloat(r.Total, 'f', -1, 64)
+		case "created_at":
+			record[i] = formatExportTime(&r.CreatedAt)
+		case "updated_at":
+			record[i] = formatExportTime(&r.UpdatedAt)
+		case "archived_at":
+			record[i] = formatExportTime(r.ArchivedAt)
+		}
+	}
+	return record, nil
+}
+
+// escapeFormula prefixes with a quote the values that spreadsheets would read as formulas
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
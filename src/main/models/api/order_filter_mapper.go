@@ -0,0 +1,119 @@
+package api
+
+import (
+	"order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// ToFilter parses the query parameters into an order filter
+func (req *ListOrdersRequest) ToFilter() (datastore.OrderFilter, error) {
+	filter := datastore.OrderFilter{
+		CustomerID: req.CustomerID,
+		SKU:        req.SKU,
+	}
+
+	// Statuses are a comma separated list, e.g. status=NEW,IN_PROGRESS
+	if req.Status != "" {
+		for _, value := range strings.Split(req.Status, ",") {
+			status := datastore.OrderStatus(strings.ToUpper(strings.TrimSpace(value)))
+			if !datastore.IsValidStatus(status) {
+				return datastore.OrderFilter{}, errors.ErrInvalidStatus
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTimeParam(req.CreatedFrom, false); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(req.CreatedTo, true); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if filter.UpdatedFrom, err = parseTimeParam(req.UpdatedFrom, false); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if filter.UpdatedTo, err = parseTimeParam(req.UpdatedTo, true); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if isInvertedRange(filter.CreatedFrom, filter.CreatedTo) || isInvertedRange(filter.UpdatedFrom, filter.UpdatedTo) {
+		return datastore.OrderFilter{}, errors.ErrInvalidDateFilter
+	}
+
+	if filter.MinTotal, err = parseTotalParam(req.MinTotal); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if filter.MaxTotal, err = parseTotalParam(req.MaxTotal); err != nil {
+		return datastore.OrderFilter{}, err
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return datastore.OrderFilter{}, errors.ErrInvalidTotalFilter
+	}
+
+	return filter, nil
+}
+
+// ToSort parses the sort keys, e.g. sort=status,-created_at. A leading minus sorts the
+// field in descending order.
+func (req *ListOrdersRequest) ToSort() ([]datastore.SortField, error) {
+	if req.Sort == "" {
+		return nil, nil
+	}
+
+	var sort []datastore.SortField
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(req.Sort, ",") {
+		key = strings.TrimSpace(key)
+		field := datastore.SortField{Field: strings.TrimPrefix(key, "-"), Descending: strings.HasPrefix(key, "-")}
+		if !datastore.IsSortable(field.Field) || seen[field.Field] {
+			return nil, errors.ErrInvalidSort
+		}
+		seen[field.Field] = true
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a YYYY-MM-DD date. Dates used as the end
+// of a range include the whole day.
+func parseTimeParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, errors.ErrInvalidDateFilter
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// parseTotalParam parses a non-negative order total
+func parseTotalParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	total, err := strconv.ParseFloat(value, 64)
+	if err != nil || total < 0 {
+		return nil, errors.ErrInvalidTotalFilter
+	}
+	return &total, nil
+}
+
+func isInvertedRange(from, to *time.Time) bool {
+	return from != nil && to != nil && from.After(*to)
+}
@@ -9,12 +9,14 @@ import (
 
 func (req *CreateOrderRequest) ToDomain() *datastore.Order {
 	return &datastore.Order{
-		CustomerID: req.CustomerID,
-		OrderID:    utils.GenerateOrderID(),
-		Status:     datastore.StatusNew,
-		Items:      req.ToOrderItem(),
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		CustomerID:      req.CustomerID,
+		CustomerName:    req.CustomerName,
+		ShippingAddress: req.ShippingAddress,
+		OrderID:         utils.GenerateOrderID(),
+		Status:          datastore.StatusNew,
+		Items:           req.ToOrderItem(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 }
 
@@ -51,11 +53,79 @@ func newItemFromDomain(item []datastore.OrderItem) []Items {
 
 func NewOrderResponse(order *datastore.Order) *OrderResponse {
 	return &OrderResponse{
-		CustomerID: order.CustomerID,
-		OrderID:    order.OrderID,
-		Status:     string(order.Status),
-		Items:      newItemFromDomain(order.Items),
-		CreatedAt:  order.CreatedAt,
-		UpdatedAt:  time.Now(),
+		CustomerID:      order.CustomerID,
+		CustomerName:    order.CustomerName,
+		ShippingAddress: order.ShippingAddress,
+		OrderID:         order.OrderID,
+		Status:          string(order.Status),
+		Items:           newItemFromDomain(order.Items),
+		Total:           order.Total,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+		ArchivedAt:      order.ArchivedAt,
+	}
+}
+
+// NewOrderResponseWith maps an order read with the given options, keeping only the selected
+// fields and inlining the expanded resources
+func NewOrderResponseWith(order *datastore.Order, read datastore.ReadOptions) *OrderResponse {
+	response := NewOrderResponse(order)
+	response.fields = read.Fields
+
+	if read.Expands(datastore.ExpandHistory) {
+		response.History = make([]StatusChangeResponse, len(order.StatusHistory))
+		for i, change := range order.StatusHistory {
+			response.History[i] = StatusChangeResponse{
+				Status:    string(change.Status),
+				ChangedAt: change.ChangedAt,
+			}
+		}
+	}
+
+	return response
+}
+
+func NewListOrdersResponse(page *datastore.OrderPage, opts datastore.ListOptions) *ListOrdersResponse {
+	data := make([]*OrderResponse, len(page.Orders))
+	for i, order := range page.Orders {
+		data[i] = NewOrderResponseWith(order, opts.Read)
+	}
+
+	response := &ListOrdersResponse{
+		Data:    data,
+		Limit:   opts.Limit,
+		Total:   page.Total,
+		HasMore: page.HasMore,
+	}
+	if !opts.CursorMode {
+		response.Page = opts.Page
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+	if page.PrevCursor != nil {
+		response.PrevCursor = page.PrevCursor.Encode()
+	}
+	return response
+}
+
+func NewSearchOrdersResponse(query string, hits []*datastore.SearchHit) *SearchOrdersResponse {
+	data := make([]*SearchHitResponse, len(hits))
+	for i, hit := range hits {
+		data[i] = &SearchHitResponse{
+			Order:      NewOrderResponse(hit.Order),
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		}
+	}
+	return &SearchOrdersResponse{Query: query, Data: data}
+}
+
+func NewCustomerSummaryResponse(summary *datastore.CustomerSummary) *CustomerSummaryResponse {
+	return &CustomerSummaryResponse{
+		CustomerID:  summary.CustomerID,
+		OrderCount:  summary.OrderCount,
+		TotalSpent:  summary.TotalSpent,
+		LastOrderAt: summary.LastOrderAt,
 	}
 }
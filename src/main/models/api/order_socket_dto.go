@@ -0,0 +1,40 @@
+package api
+
+// Types of the messages exchanged over the order WebSocket
+const (
+	// SocketSubscribe subscribes to the status changes of orders, resuming after LastEventID when set
+	SocketSubscribe = "subscribe"
+	// SocketUnsubscribe stops the status changes of orders
+	SocketUnsubscribe = "unsubscribe"
+	// SocketDeliver acknowledges the delivery of an order, moving it to DELIVERED
+	SocketDeliver = "deliver"
+
+	// SocketAck confirms a request of the client
+	SocketAck = "ack"
+	// SocketError rejects a request of the client
+	SocketError = "error"
+	// SocketEvent carries an event of a subscribed order
+	SocketEvent = "event"
+)
+
+// SocketRequest is a message sent by a client over the order WebSocket. Its ID, chosen by the
+// client, is echoed in the acknowledgement or error answering it.
+type SocketRequest struct {
+	Type        string   `json:"type"`
+	ID          string   `json:"id,omitempty"`
+	OrderIDs    []string `json:"order_ids,omitempty"`
+	OrderID     string   `json:"order_id,omitempty"`
+	LastEventID string   `json:"last_event_id,omitempty"`
+}
+
+// SocketMessage is a message sent to a client over the order WebSocket: an acknowledgement or
+// an error answering the request with the same ID, or an event of a subscribed order
+type SocketMessage struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Event   string      `json:"event,omitempty"`
+	EventID string      `json:"event_id,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
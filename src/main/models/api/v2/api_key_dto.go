@@ -0,0 +1,30 @@
+package v2
+
+import "time"
+
+// IssueAPIKeyRequest represents the request body for issuing an API key. Allowed IPs are
+// addresses or CIDR ranges; keys without any are accepted from every address.
+type IssueAPIKeyRequest struct {
+	Name       string   `json:"name" binding:"required,max=100" maxLength:"100"`
+	Scopes     []string `json:"scopes" binding:"required,min=1,unique,dive,oneof=orders:read orders:write orders:status" enums:"orders:read,orders:write,orders:status"`
+	AllowedIPs []string `json:"allowed_ips,omitempty" binding:"omitempty,dive,cidr|ip" example:"203.0.113.0/24"`
+}
+
+// APIKeyResponse represents an API key. The key itself is only returned when it is issued or
+// rotated, and cannot be retrieved afterwards.
+type APIKeyResponse struct {
+	KeyID      string     `json:"key_id" example:"3f2a9c1e7b4d5a60"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty" example:"omk_3f2a9c1e7b4d5a60_9mK2..."`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ListAPIKeysResponse represents the API keys of a tenant
+type ListAPIKeysResponse struct {
+	Data []*APIKeyResponse `json:"data"`
+}
@@ -0,0 +1,38 @@
+package v2
+
+import "order-management-ms/src/main/models/datastore"
+
+// ToDomain maps the request to a new API key. The key ID and the hash are assigned by the
+// service.
+func (req *IssueAPIKeyRequest) ToDomain() *datastore.APIKey {
+	return &datastore.APIKey{
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+	}
+}
+
+// NewAPIKeyResponse maps a stored API key, with the plain key when it was just issued or
+// rotated
+func NewAPIKeyResponse(key *datastore.APIKey, plainKey string) *APIKeyResponse {
+	return &APIKeyResponse{
+		KeyID:      key.KeyID,
+		Name:       key.Name,
+		Key:        plainKey,
+		Scopes:     key.Scopes,
+		AllowedIPs: key.AllowedIPs,
+		CreatedAt:  key.CreatedAt,
+		RotatedAt:  key.RotatedAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+// NewListAPIKeysResponse maps the API keys of a tenant
+func NewListAPIKeysResponse(keys []*datastore.APIKey) *ListAPIKeysResponse {
+	data := make([]*APIKeyResponse, len(keys))
+	for i, key := range keys {
+		data[i] = NewAPIKeyResponse(key, "")
+	}
+	return &ListAPIKeysResponse{Data: data}
+}
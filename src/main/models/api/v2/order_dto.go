@@ -0,0 +1,134 @@
+// Package v2 holds the request and response bodies of the version 2 of the REST API.
+// Amounts are decimal strings and listings are wrapped in a data, meta and links envelope.
+package v2
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateOrderRequest represents the request body for creating an order
+type CreateOrderRequest struct {
+	CustomerID      string        `json:"customer_id" binding:"required,customer_id" maxLength:"64"`
+	CustomerName    string        `json:"customer_name,omitempty"`
+	ShippingAddress string        `json:"shipping_address,omitempty"`
+	Items           []ItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// ItemRequest represents an item of a new order. Prices are set by the service.
+type ItemRequest struct {
+	Sku      string `json:"sku" binding:"required,sku" maxLength:"64"`
+	Quantity int    `json:"quantity" binding:"gt=0" minimum:"1"`
+}
+
+// UpdateOrderStatusRequest represents the request body for updating order status
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// OrderResponse represents the response body for an order. Amounts are decimal strings with
+// two decimals, e.g. "21.98".
+type OrderResponse struct {
+	OrderID         string         `json:"order_id"`
+	CustomerID      string         `json:"customer_id"`
+	CustomerName    string         `json:"customer_name,omitempty"`
+	ShippingAddress string         `json:"shipping_address,omitempty"`
+	Status          string         `json:"status"`
+	Items           []ItemResponse `json:"items"`
+	Total           string         `json:"total" example:"21.98"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	ArchivedAt      *time.Time     `json:"archived_at,omitempty"`
+
+	// Expanded related resources
+	History []StatusChangeResponse `json:"history,omitempty"`
+
+	// fields limits the JSON encoding to a sparse fieldset, every field when empty
+	fields []string
+}
+
+// ItemResponse represents an item of an order
+type ItemResponse struct {
+	Sku      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+	Price    string `json:"price" example:"10.99"`
+}
+
+// StatusChangeResponse represents an entry of the status history of an order
+type StatusChangeResponse struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// MarshalJSON encodes the order, keeping only the selected fields and the expanded
+// resources when the response is sparse
+func (r OrderResponse) MarshalJSON() ([]byte, error) {
+	type plainOrderResponse OrderResponse
+	encoded, err := json.Marshal(plainOrderResponse(r))
+	if err != nil || len(r.fields) == 0 {
+		return encoded, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+
+	sparse := make(map[string]json.RawMessage, len(r.fields)+1)
+	for _, field := range append(r.fields, "history") {
+		if value, ok := all[field]; ok {
+			sparse[field] = value
+		}
+	}
+	return json.Marshal(sparse)
+}
+
+// ListOrdersResponse represents a page of orders
+type ListOrdersResponse struct {
+	Data  []*OrderResponse `json:"data"`
+	Meta  PageMeta         `json:"meta"`
+	Links PageLinks        `json:"links"`
+}
+
+// PageMeta represents the pagination metadata of a page. Page is only set for page
+// pagination and the cursors only for cursor pagination; Total is omitted when the count was
+// skipped.
+type PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// PageLinks represents the links to the adjacent pages
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// SearchOrdersRequest represents the query parameters for searching orders
+type SearchOrdersRequest struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit,default=20"`
+}
+
+// SearchOrdersResponse represents the orders matching a search, most relevant first
+type SearchOrdersResponse struct {
+	Data []*SearchHitResponse `json:"data"`
+	Meta SearchMeta           `json:"meta"`
+}
+
+// SearchMeta represents the metadata of a search
+type SearchMeta struct {
+	Query string `json:"query"`
+}
+
+// SearchHitResponse represents an order matching a search, with its relevance score and the
+// matching fields highlighted with <em> tags
+type SearchHitResponse struct {
+	Order      *OrderResponse      `json:"order"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights"`
+}
@@ -0,0 +1,98 @@
+package v2
+
+import (
+	"strconv"
+
+	v1 "order-management-ms/src/main/models/api"
+	"order-management-ms/src/main/models/datastore"
+)
+
+// ToDomain maps the request to a new order. Identifiers and prices are assigned like in v1.
+func (req *CreateOrderRequest) ToDomain() *datastore.Order {
+	items := make([]v1.Items, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = v1.Items{Sku: item.Sku, Quantity: item.Quantity}
+	}
+
+	return (&v1.CreateOrderRequest{
+		CustomerID:      req.CustomerID,
+		CustomerName:    req.CustomerName,
+		ShippingAddress: req.ShippingAddress,
+		Items:           items,
+	}).ToDomain()
+}
+
+// NewOrderResponse maps an order returned by the service, keeping only the given fields when
+// any is set
+func NewOrderResponse(order *v1.OrderResponse, fields []string) *OrderResponse {
+	items := make([]ItemResponse, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = ItemResponse{
+			Sku:      item.Sku,
+			Quantity: item.Quantity,
+			Price:    formatAmount(item.Price),
+		}
+	}
+
+	var history []StatusChangeResponse
+	if order.History != nil {
+		history = make([]StatusChangeResponse, len(order.History))
+		for i, change := range order.History {
+			history[i] = StatusChangeResponse(change)
+		}
+	}
+
+	return &OrderResponse{
+		OrderID:         order.OrderID,
+		CustomerID:      order.CustomerID,
+		CustomerName:    order.CustomerName,
+		ShippingAddress: order.ShippingAddress,
+		Status:          order.Status,
+		Items:           items,
+		Total:           formatAmount(order.Total),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+		ArchivedAt:      order.ArchivedAt,
+		History:         history,
+		fields:          fields,
+	}
+}
+
+// NewListOrdersResponse maps a page of orders returned by the service
+func NewListOrdersResponse(page *v1.ListOrdersResponse, fields []string) *ListOrdersResponse {
+	data := make([]*OrderResponse, len(page.Data))
+	for i, order := range page.Data {
+		data[i] = NewOrderResponse(order, fields)
+	}
+
+	return &ListOrdersResponse{
+		Data: data,
+		Meta: PageMeta{
+			Page:       page.Page,
+			Limit:      page.Limit,
+			Total:      page.Total,
+			HasMore:    page.HasMore,
+			NextCursor: page.NextCursor,
+			PrevCursor: page.PrevCursor,
+		},
+		Links: PageLinks{Next: page.Next, Prev: page.Prev},
+	}
+}
+
+// NewSearchOrdersResponse maps the search results returned by the service
+func NewSearchOrdersResponse(results *v1.SearchOrdersResponse) *SearchOrdersResponse {
+	data := make([]*SearchHitResponse, len(results.Data))
+	for i, hit := range results.Data {
+		data[i] = &SearchHitResponse{
+			Order:      NewOrderResponse(hit.Order, nil),
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		}
+	}
+	return &SearchOrdersResponse{Data: data, Meta: SearchMeta{Query: results.Query}}
+}
+
+// formatAmount formats an amount as a decimal string with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
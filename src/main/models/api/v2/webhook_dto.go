@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest represents the request body for creating a webhook. A secret is
+// generated when none is sent.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2048" maxLength:"2048" example:"https://partner.example.com/hooks/orders"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,unique,dive,oneof=order.status_changed" enums:"order.status_changed"`
+	Secret     string   `json:"secret,omitempty" binding:"omitempty,min=16,max=256" minLength:"16" maxLength:"256"`
+}
+
+// WebhookResponse represents a webhook. The secret is only returned when the webhook is
+// created.
+type WebhookResponse struct {
+	WebhookID  string    `json:"webhook_id" example:"0b6c7e2e-5d0c-4f39-9a47-3f0c5d1e2a8b"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty" example:"whsec_9mK2..."`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListWebhooksResponse represents the webhooks of a tenant
+type ListWebhooksResponse struct {
+	Data []*WebhookResponse `json:"data"`
+}
+
+// WebhookDeliveryResponse represents an event sent to a webhook, with its attempts. Pending
+// deliveries are attempted again at next_attempt_at.
+type WebhookDeliveryResponse struct {
+	DeliveryID    string                     `json:"delivery_id"`
+	WebhookID     string                     `json:"webhook_id"`
+	EventType     string                     `json:"event_type" example:"order.status_changed"`
+	Status        string                     `json:"status" enums:"PENDING,SUCCEEDED,DEAD_LETTER"`
+	Attempts      int                        `json:"attempts"`
+	NextAttemptAt *time.Time                 `json:"next_attempt_at,omitempty"`
+	Payload       json.RawMessage            `json:"payload" swaggertype:"object"`
+	Log           []*DeliveryAttemptResponse `json:"log"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	DeliveredAt   *time.Time                 `json:"delivered_at,omitempty"`
+}
+
+// DeliveryAttemptResponse represents an attempt to deliver an event, with the status line of
+// the webhook response or the error that prevented one
+type DeliveryAttemptResponse struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty" example:"503"`
+	Response   string    `json:"response,omitempty" example:"503 Service Unavailable"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// ListWebhookDeliveriesResponse represents the latest deliveries of a webhook
+type ListWebhookDeliveriesResponse struct {
+	Data []*WebhookDeliveryResponse `json:"data"`
+}
@@ -0,0 +1,78 @@
+package v2
+
+import (
+	"encoding/json"
+
+	"order-management-ms/src/main/models/datastore"
+)
+
+// ToDomain maps the request to a new webhook. The webhook ID, and the secret when none was
+// sent, are assigned by the service.
+func (req *CreateWebhookRequest) ToDomain() *datastore.Webhook {
+	return &datastore.Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	}
+}
+
+// NewWebhookResponse maps a stored webhook, with its secret when it was just created
+func NewWebhookResponse(webhook *datastore.Webhook, withSecret bool) *WebhookResponse {
+	response := &WebhookResponse{
+		WebhookID:  webhook.WebhookID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+	if withSecret {
+		response.Secret = webhook.Secret
+	}
+	return response
+}
+
+// NewListWebhooksResponse maps the webhooks of a tenant
+func NewListWebhooksResponse(webhooks []*datastore.Webhook) *ListWebhooksResponse {
+	data := make([]*WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		data[i] = NewWebhookResponse(webhook, false)
+	}
+	return &ListWebhooksResponse{Data: data}
+}
+
+// NewWebhookDeliveryResponse maps a stored delivery
+func NewWebhookDeliveryResponse(delivery *datastore.WebhookDelivery) *WebhookDeliveryResponse {
+	response := &WebhookDeliveryResponse{
+		DeliveryID:  delivery.DeliveryID,
+		WebhookID:   delivery.WebhookID,
+		EventType:   delivery.EventType,
+		Status:      string(delivery.Status),
+		Attempts:    delivery.Attempts,
+		Payload:     json.RawMessage(delivery.Payload),
+		Log:         make([]*DeliveryAttemptResponse, len(delivery.Log)),
+		CreatedAt:   delivery.CreatedAt,
+		DeliveredAt: delivery.DeliveredAt,
+	}
+	if delivery.Status == datastore.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	for i, attempt := range delivery.Log {
+		response.Log[i] = &DeliveryAttemptResponse{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Response:   attempt.Response,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		}
+	}
+	return response
+}
+
+// NewListWebhookDeliveriesResponse maps the deliveries of a webhook
+func NewListWebhookDeliveriesResponse(deliveries []*datastore.WebhookDelivery) *ListWebhookDeliveriesResponse {
+	data := make([]*WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		data[i] = NewWebhookDeliveryResponse(delivery)
+	}
+	return &ListWebhookDeliveriesResponse{Data: data}
+}
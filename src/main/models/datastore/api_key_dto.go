@@ -0,0 +1,25 @@
+package datastore
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey is a key of a partner system, bound to a tenant and to scopes. Only the hash of the
+// key is stored; after a rotation the previous hash stays valid until PreviousExpiresAt.
+type APIKey struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	KeyID             string             `bson:"key_id" json:"key_id"`
+	TenantID          string             `bson:"tenant_id" json:"tenant_id"`
+	Name              string             `bson:"name" json:"name"`
+	Hash              string             `bson:"hash" json:"-"`
+	PreviousHash      string             `bson:"previous_hash,omitempty" json:"-"`
+	PreviousExpiresAt *time.Time         `bson:"previous_expires_at,omitempty" json:"-"`
+	Scopes            []string           `bson:"scopes" json:"scopes"`
+	AllowedIPs        []string           `bson:"allowed_ips,omitempty" json:"allowed_ips,omitempty"`
+	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
+	RotatedAt         *time.Time         `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"`
+	RevokedAt         *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	LastUsedAt        *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,13 +17,25 @@ const (
 )
 
 type Order struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	OrderID    string             `bson:"order_id" json:"order_id"`
-	CustomerID string             `bson:"customer_id" json:"customer_id"`
-	Status     OrderStatus        `bson:"status" json:"status"`
-	Items      []OrderItem        `bson:"items" json:"items"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID        string             `bson:"tenant_id" json:"tenant_id"`
+	OrderID         string             `bson:"order_id" json:"order_id"`
+	CustomerID      string             `bson:"customer_id" json:"customer_id"`
+	CustomerName    string             `bson:"customer_name,omitempty" json:"customer_name,omitempty"`
+	ShippingAddress string             `bson:"shipping_address,omitempty" json:"shipping_address,omitempty"`
+	Status          OrderStatus        `bson:"status" json:"status"`
+	Items           []OrderItem        `bson:"items" json:"items"`
+	Total           float64            `bson:"total" json:"total"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+	ArchivedAt      *time.Time         `bson:"archived_at,omitempty" json:"archived_at,omitempty"`
+	StatusHistory   []StatusChange     `bson:"status_history,omitempty" json:"status_history,omitempty"`
+}
+
+// StatusChange records when an order moved to a status
+type StatusChange struct {
+	Status    OrderStatus `bson:"status" json:"status"`
+	ChangedAt time.Time   `bson:"changed_at" json:"changed_at"`
 }
 
 type OrderItem struct {
@@ -31,6 +44,34 @@ type OrderItem struct {
 	Price    float64 `bson:"price" json:"price"`
 }
 
+// ArchivableStatuses are the final statuses whose orders can be moved to cold storage
+var ArchivableStatuses = []OrderStatus{StatusDelivered, StatusCancelled}
+
+// CalculateTotal returns the sum of price times quantity of the order items, rounded to cents
+func (o *Order) CalculateTotal() float64 {
+	total := 0.0
+	for _, item := range o.Items {
+		total += item.Price * float64(item.Quantity)
+	}
+	return math.Round(total*100) / 100
+}
+
+// CustomerSummary aggregates the orders of a customer
+type CustomerSummary struct {
+	CustomerID  string    `bson:"_id"`
+	OrderCount  int64     `bson:"order_count"`
+	TotalSpent  float64   `bson:"total_spent"`
+	LastOrderAt time.Time `bson:"last_order_at"`
+}
+
+// SearchHit is an order matching a search query. Score ranks the hits by relevance and
+// Highlights holds the matching fields with the matched terms wrapped in <em> tags.
+type SearchHit struct {
+	Order      *Order
+	Score      float64
+	Highlights map[string][]string
+}
+
 func IsValidStatus(status OrderStatus) bool {
 	switch status {
 	case StatusNew, StatusInProgress, StatusDelivered, StatusCancelled:
@@ -0,0 +1,172 @@
+package datastore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrderFilter describes the orders to include in a listing. Zero values do not filter.
+type OrderFilter struct {
+	Statuses    []OrderStatus
+	CustomerID  string
+	SKU         string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	MinTotal    *float64
+	MaxTotal    *float64
+	// IncludeArchived also matches the archived orders. Only exports read the archive.
+	IncludeArchived bool
+}
+
+// Sortable fields of the order listings
+const (
+	SortByCreatedAt  = "created_at"
+	SortByUpdatedAt  = "updated_at"
+	SortByStatus     = "status"
+	SortByTotal      = "total"
+	SortByCustomerID = "customer_id"
+)
+
+// SortField is one key of the listing order
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// IsSortable checks if listings can be sorted by the field
+func IsSortable(field string) bool {
+	switch field {
+	case SortByCreatedAt, SortByUpdatedAt, SortByStatus, SortByTotal, SortByCustomerID:
+		return true
+	default:
+		return false
+	}
+}
+
+// Selectable fields of an order, named as in both the documents and the responses
+var selectableFields = map[string]bool{
+	"order_id":         true,
+	"customer_id":      true,
+	"customer_name":    true,
+	"shipping_address": true,
+	"status":           true,
+	"items":            true,
+	"total":            true,
+	"created_at":       true,
+	"updated_at":       true,
+	"archived_at":      true,
+}
+
+// Related resources that can be inlined in an order
+const (
+	ExpandHistory = "history"
+)
+
+// IsSelectableField checks if the field can be requested in a sparse fieldset
+func IsSelectableField(field string) bool {
+	return selectableFields[field]
+}
+
+// IsExpandable checks if the related resource can be inlined in an order
+func IsExpandable(resource string) bool {
+	return resource == ExpandHistory
+}
+
+// ReadOptions selects the parts of the orders to read. Empty Fields reads every field;
+// Expand lists the related resources to inline, which are left out otherwise.
+type ReadOptions struct {
+	Fields []string
+	Expand []string
+}
+
+// Expands checks if the related resource was requested
+func (o ReadOptions) Expands(resource string) bool {
+	for _, expand := range o.Expand {
+		if expand == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDefault checks if the options read every field and no related resource
+func (o ReadOptions) IsDefault() bool {
+	return len(o.Fields) == 0 && len(o.Expand) == 0
+}
+
+// ListOptions describes which page of orders to return. When CursorMode is set, the page
+// starts at Cursor (or at the newest order when Cursor is nil) and Page is ignored.
+// SkipTotal avoids counting the matching orders, which is expensive on large collections.
+// An empty Sort lists the newest orders first. Read selects the parts of the orders returned.
+type ListOptions struct {
+	Page       int
+	Limit      int
+	CursorMode bool
+	Cursor     *Cursor
+	SkipTotal  bool
+	Sort       []SortField
+	Read       ReadOptions
+}
+
+// IsDefaultSort checks if the options list the newest orders first, the only order
+// supported by cursor pagination
+func (o ListOptions) IsDefaultSort() bool {
+	return len(o.Sort) == 0 ||
+		(len(o.Sort) == 1 && o.Sort[0] == SortField{Field: SortByCreatedAt, Descending: true})
+}
+
+// OrderPage is a page of orders, with the cursors to the adjacent pages when paginating by cursor.
+// Total is the number of orders matching the filter, or nil when it was not counted.
+type OrderPage struct {
+	Orders     []*Order
+	HasMore    bool
+	Total      *int64
+	NextCursor *Cursor
+	PrevCursor *Cursor
+}
+
+// Cursor is a position in the (created_at, _id) ordering of the orders. Backward cursors
+// return the page of orders before the position instead of after it.
+type Cursor struct {
+	CreatedAt time.Time          `json:"t"`
+	ID        primitive.ObjectID `json:"id"`
+	Backward  bool               `json:"b,omitempty"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// NewCursor creates a cursor positioned at the given order
+func NewCursor(order *Order, backward bool) *Cursor {
+	return &Cursor{
+		CreatedAt: order.CreatedAt,
+		ID:        order.ID,
+		Backward:  backward,
+	}
+}
+
+// Encode returns the opaque string representation of the cursor
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor created by Cursor.Encode
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID.IsZero() {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,61 @@
+package datastore
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are attempted once their next attempt is due
+	DeliveryPending DeliveryStatus = "PENDING"
+	// DeliverySucceeded deliveries were accepted by the webhook
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	// DeliveryDeadLetter deliveries failed every attempt, and are only retried on request
+	DeliveryDeadLetter DeliveryStatus = "DEAD_LETTER"
+)
+
+// DeliveryStatuses are the valid delivery statuses
+var DeliveryStatuses = []DeliveryStatus{DeliveryPending, DeliverySucceeded, DeliveryDeadLetter}
+
+// Webhook is a URL of a partner system called with the order events of a tenant. The secret
+// signs the deliveries, so it is stored as is.
+type Webhook struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID  string             `bson:"webhook_id" json:"webhook_id"`
+	TenantID   string             `bson:"tenant_id" json:"tenant_id"`
+	URL        string             `bson:"url" json:"url"`
+	EventTypes []string           `bson:"event_types" json:"event_types"`
+	Secret     string             `bson:"secret" json:"-"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// WebhookDelivery is an event sent to a webhook, with the log of its attempts. The payload is
+// kept as sent, so redeliveries carry the same body.
+type WebhookDelivery struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeliveryID    string             `bson:"delivery_id" json:"delivery_id"`
+	TenantID      string             `bson:"tenant_id" json:"tenant_id"`
+	WebhookID     string             `bson:"webhook_id" json:"webhook_id"`
+	EventType     string             `bson:"event_type" json:"event_type"`
+	Payload       string             `bson:"payload" json:"payload"`
+	Status        DeliveryStatus     `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	Log           []DeliveryAttempt  `bson:"log" json:"log"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	DeliveredAt   *time.Time         `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// DeliveryAttempt is an attempt to deliver an event, with the status line of the webhook
+// response or the error that prevented one
+type DeliveryAttempt struct {
+	At         time.Time     `bson:"at" json:"at"`
+	StatusCode int           `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Response   string        `bson:"response,omitempty" json:"response,omitempty"`
+	Error      string        `bson:"error,omitempty" json:"error,omitempty"`
+	Duration   time.Duration `bson:"duration" json:"duration"`
+}
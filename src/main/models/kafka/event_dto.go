@@ -13,17 +13,19 @@ type EventPublisher interface {
 }
 
 type OrderStatusChangedEvent struct {
-	OrderID   string                `json:"order_id"`
-	OldStatus datastore.OrderStatus `json:"old_status"`
-	NewStatus datastore.OrderStatus `json:"new_status"`
-	Timestamp string                `json:"timestamp"`
+	OrderID    string                `json:"order_id"`
+	CustomerID string                `json:"customer_id"`
+	OldStatus  datastore.OrderStatus `json:"old_status"`
+	NewStatus  datastore.OrderStatus `json:"new_status"`
+	Timestamp  string                `json:"timestamp"`
 }
 
-func NewOrderStatusChangedEvent(orderID string, oldStatus, newStatus datastore.OrderStatus) OrderStatusChangedEvent {
+func NewOrderStatusChangedEvent(orderID, customerID string, oldStatus, newStatus datastore.OrderStatus) OrderStatusChangedEvent {
 	return OrderStatusChangedEvent{
-		OrderID:   orderID,
-		OldStatus: oldStatus,
-		NewStatus: newStatus,
-		Timestamp: time.Now().Format(time.RFC3339),
+		OrderID:    orderID,
+		CustomerID: customerID,
+		OldStatus:  oldStatus,
+		NewStatus:  newStatus,
+		Timestamp:  time.Now().Format(time.RFC3339),
 	}
 }
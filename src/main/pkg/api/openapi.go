@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"order-management-ms/src/main/pkg/customerrors"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// docsPage renders the specification with Swagger UI
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Order Management API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+// openAPIHandler serves the OpenAPI 3 specification
+func openAPIHandler(doc *openapi3.T) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// docsHandler serves the interactive documentation
+func docsHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, docsPage)
+}
+
+// requestValidationMiddleware rejects the request bodies that do not match the schema of
+// their operation in the specification. Routes missing from the specification are not
+// checked, and query parameters are left to the handlers, which know their defaults.
+func requestValidationMiddleware(doc *openapi3.T, logger *zap.Logger) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		ExcludeRequestQueryParams: true,
+		MultiError:                true,
+		AuthenticationFunc:        openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			logger.Warn("Request does not match the API specification", zap.Error(err))
+			validationErr := customerrors.NewValidationError("request does not match the API specification", toValidationErrors(err))
+			_ = c.Error(validationErr)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// toValidationErrors lists the invalid fields of a validation error, named by their JSON path
+func toValidationErrors(err error) []customerrors.ValidationError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var fields []customerrors.ValidationError
+		for _, e := range multi {
+			fields = append(fields, toValidationErrors(e)...)
+		}
+		return fields
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []customerrors.ValidationError{{
+			Field:   fieldPath(schemaErr.JSONPointer()),
+			Message: schemaErr.Reason,
+		}}
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		field := "body"
+		if requestErr.Parameter != nil {
+			field = requestErr.Parameter.Name
+		}
+		return []customerrors.ValidationError{{Field: field, Message: requestErr.Reason}}
+	}
+
+	return []customerrors.ValidationError{{Message: err.Error()}}
+}
+
+// fieldPath formats a JSON pointer like the binding errors, e.g. items[0].sku
+func fieldPath(pointer []string) string {
+	var path strings.Builder
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			path.WriteString("[" + segment + "]")
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(segment)
+	}
+	return path.String()
+}
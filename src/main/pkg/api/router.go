@@ -4,29 +4,97 @@ import (
 	"net/http"
 	"time"
 
+	"order-management-ms/src/main/config"
 	ordercontroller "order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/docs"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/ratelimit"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// SetupRouter configure the router
-func SetupRouter(orderCtrl *ordercontroller.OrderController, logger *zap.Logger) *gin.Engine {
+// SetupRouter configure the router. The order and GraphQL routes are protected by authenticate,
+// unless it is nil, and rate limited per route group by limiter, unless it is nil, like the
+// failed authentications of each client IP. Request bodies are validated once authenticated. The API key
+// administration, order event and webhook administration routes are only served when
+// apiKeyCtrl, eventsCtrl and webhookCtrl are set.
+func SetupRouter(cfg *config.Config, orderCtrl *ordercontroller.OrderController, orderV2Ctrl *ordercontroller.OrderControllerV2, graphqlCtrl *ordercontroller.GraphQLController, apiKeyCtrl *ordercontroller.APIKeyController, eventsCtrl *ordercontroller.OrderEventsController, webhookCtrl *ordercontroller.WebhookController, authenticate gin.HandlerFunc, limiter *ratelimit.Limiter, logger *zap.Logger) *gin.Engine {
 	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
 
 	// Middleware
 	r.Use(gin.Recovery())
+	r.Use(requestid.Middleware())
 	r.Use(loggingMiddleware(logger))
 	r.Use(jsonContentTypeMiddleware())
+	r.Use(customerrors.ErrorHandler())
+
+	// API specification, also used to validate the request bodies once authenticated
+	spec, err := docs.OpenAPI()
+	if err != nil {
+		logger.Fatal("Failed to load the OpenAPI specification", zap.Error(err))
+	}
+	validateRequest, err := requestValidationMiddleware(spec, logger)
+	if err != nil {
+		logger.Fatal("Failed to build the request validator", zap.Error(err))
+	}
+	r.GET("/api/v1/openapi.json", openAPIHandler(spec))
+	r.GET("/api/v1/docs", docsHandler)
+
+	// Rate limits of the route groups, counted per client
+	limit := func(group string) gin.HandlerFunc {
+		return ratelimit.Middleware(limiter, cfg.RateLimit, group, logger)
+	}
+
+	// Failed authentications are limited per client IP before the credentials are checked
+	if authenticate != nil {
+		authenticate = ratelimit.FailedAuthentication(limiter, cfg.RateLimit, "auth-failures", authenticate, logger)
+	}
+
+	// API v1 routes, deprecated in favour of v2
+	setupV1Routes(r, cfg, orderCtrl, authenticate, validateRequest, limit)
+
+	// Live order events
+	if eventsCtrl != nil {
+		setupEventRoutes(r, cfg, eventsCtrl, authenticate, validateRequest, limit)
+	}
+
+	// Order exports
+	setupExportRoutes(r, cfg, orderCtrl, authenticate, validateRequest, limit)
 
-	// API v1 routes
-	setupV1Routes(r, orderCtrl)
+	// API v2 routes
+	setupV2Routes(r, cfg, orderV2Ctrl, authenticate, validateRequest, limit)
+
+	// API key administration
+	if apiKeyCtrl != nil {
+		setupAdminRoutes(r, cfg, apiKeyCtrl, authenticate, validateRequest, limit)
+	}
+
+	// Webhook administration
+	if webhookCtrl != nil {
+		setupWebhookRoutes(r, cfg, webhookCtrl, authenticate, validateRequest, limit)
+	}
+
+	// GraphQL endpoint, scoped to a tenant like the order routes
+	graphqlGroup := r.Group("/graphql")
+	protect(graphqlGroup, authenticate, validateRequest)
+	graphqlGroup.Use(limit("graphql"))
+	graphqlGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+	{
+		graphqlGroup.POST("", graphqlCtrl.Query)
+		graphqlGroup.GET("", graphqlCtrl.Query)
+	}
 
 	return r
 }
 
 // setupV1Routes configure the routes for API v1
-func setupV1Routes(r *gin.Engine, orderCtrl *ordercontroller.OrderController) {
+func setupV1Routes(r *gin.Engine, cfg *config.Config, orderCtrl *ordercontroller.OrderController, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
 	v1 := r.Group("/api/v1")
 	{
 		// Health check endpoint
@@ -34,16 +102,112 @@ func setupV1Routes(r *gin.Engine, orderCtrl *ordercontroller.OrderController) {
 
 		// Order routes
 		ordersGroup := v1.Group("/orders")
+		ordersGroup.Use(deprecationMiddleware(cfg.APIVersions.V1DeprecatedAt, cfg.APIVersions.V1Sunset, "/api/v2/orders"))
+		protect(ordersGroup, authenticate, validate)
+		ordersGroup.Use(limit("orders"))
+		ordersGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
 		{
-			ordersGroup.POST("", orderCtrl.CreateOrder)
+			ordersGroup.POST("", limit("order-writes"), orderCtrl.CreateOrder)
+			ordersGroup.GET("/search", orderCtrl.SearchOrders)
 			ordersGroup.GET("/:id", orderCtrl.GetOrder)
 			ordersGroup.GET("", orderCtrl.ListOrders)
-			ordersGroup.PATCH("/:id/status", orderCtrl.UpdateOrderStatus)
+			ordersGroup.PATCH("/:id/status", limit("order-writes"), orderCtrl.UpdateOrderStatus)
 		}
 	}
 }
 
+// setupEventRoutes serves the streams of the order events. They share the path of the v1
+// order routes, but not their deprecation.
+func setupEventRoutes(r *gin.Engine, cfg *config.Config, eventsCtrl *ordercontroller.OrderEventsController, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
+	eventsGroup := r.Group("/api/v1/orders")
+	protect(eventsGroup, authenticate, validate)
+	eventsGroup.Use(limit("orders"))
+	eventsGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+	{
+		eventsGroup.GET("/events", eventsCtrl.StreamOrderEvents)
+		eventsGroup.GET("/:id/events", eventsCtrl.StreamOrderEventsByID)
+		eventsGroup.GET("/ws", eventsCtrl.ServeOrderSocket)
+	}
+}
+
+// setupExportRoutes serves the order exports. They share the path of the v1 order routes, but
+// not their deprecation, and are limited in their own group as each export reads many orders.
+func setupExportRoutes(r *gin.Engine, cfg *config.Config, orderCtrl *ordercontroller.OrderController, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
+	exportGroup := r.Group("/api/v1/orders")
+	protect(exportGroup, authenticate, validate)
+	exportGroup.Use(limit("exports"))
+	exportGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+	{
+		exportGroup.GET("/export", orderCtrl.ExportOrders)
+	}
+}
+
+// setupV2Routes configure the routes for API v2
+func setupV2Routes(r *gin.Engine, cfg *config.Config, orderCtrl *ordercontroller.OrderControllerV2, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
+	v2 := r.Group("/api/v2")
+	{
+		// Order routes
+		ordersGroup := v2.Group("/orders")
+		protect(ordersGroup, authenticate, validate)
+		ordersGroup.Use(limit("orders"))
+		ordersGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+		{
+			ordersGroup.POST("", limit("order-writes"), orderCtrl.CreateOrder)
+			ordersGroup.GET("/search", orderCtrl.SearchOrders)
+			ordersGroup.GET("/:id", orderCtrl.GetOrder)
+			ordersGroup.GET("", orderCtrl.ListOrders)
+			ordersGroup.PATCH("/:id/status", limit("order-writes"), orderCtrl.UpdateOrderStatus)
+			ordersGroup.POST("/:id/cancel", limit("order-writes"), orderCtrl.CancelOrder)
+		}
+	}
+}
+
+// setupAdminRoutes configure the administration routes, scoped to a tenant like the order
+// routes
+func setupAdminRoutes(r *gin.Engine, cfg *config.Config, apiKeyCtrl *ordercontroller.APIKeyController, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
+	apiKeysGroup := r.Group("/api/v2/admin/api-keys")
+	protect(apiKeysGroup, authenticate, validate)
+	apiKeysGroup.Use(limit("admin"))
+	apiKeysGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+	{
+		apiKeysGroup.POST("", apiKeyCtrl.IssueAPIKey)
+		apiKeysGroup.GET("", apiKeyCtrl.ListAPIKeys)
+		apiKeysGroup.POST("/:id/rotate", apiKeyCtrl.RotateAPIKey)
+		apiKeysGroup.DELETE("/:id", apiKeyCtrl.RevokeAPIKey)
+	}
+}
+
+// setupWebhookRoutes configure the webhook administration routes, scoped to a tenant like the
+// order routes
+func setupWebhookRoutes(r *gin.Engine, cfg *config.Config, webhookCtrl *ordercontroller.WebhookController, authenticate, validate gin.HandlerFunc, limit func(string) gin.HandlerFunc) {
+	webhooksGroup := r.Group("/api/v2/admin/webhooks")
+	protect(webhooksGroup, authenticate, validate)
+	webhooksGroup.Use(limit("admin"))
+	webhooksGroup.Use(tenant.Middleware(cfg.Tenant.Header, cfg.Tenant.Default))
+	{
+		webhooksGroup.POST("", webhookCtrl.CreateWebhook)
+		webhooksGroup.GET("", webhookCtrl.ListWebhooks)
+		webhooksGroup.DELETE("/:id", webhookCtrl.DeleteWebhook)
+		webhooksGroup.GET("/:id/deliveries", webhookCtrl.ListWebhookDeliveries)
+		webhooksGroup.POST("/:id/deliveries/:delivery_id/redeliver", webhookCtrl.RedeliverWebhook)
+	}
+}
+
+// protect adds the authentication middleware to the group, when there is one, then the request
+// validation, so unauthenticated clients cannot probe the schemas of the request bodies
+func protect(group *gin.RouterGroup, authenticate, validate gin.HandlerFunc) {
+	if authenticate != nil {
+		group.Use(authenticate)
+	}
+	group.Use(validate)
+}
+
 // healthCheck handle the health check endpoint
+// @Summary Health check
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /api/v1/health [get]
 func healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
@@ -74,7 +238,7 @@ func loggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		latency := time.Since(start)
 		status := c.Writer.Status()
 
-		logger.Info("Request processed",
+		requestid.Logger(c.Request.Context(), logger).Info("Request processed",
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", status),
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deprecationMiddleware announces that the routes of the group are deprecated since
+// deprecatedAt (RFC 9745) and will be removed at sunset (RFC 8594), linking to the successor
+// version. Zero times are not announced.
+func deprecationMiddleware(deprecatedAt, sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !deprecatedAt.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+		}
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
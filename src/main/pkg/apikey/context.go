@@ -0,0 +1,16 @@
+package apikey
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the ID of the API key of the caller
+func NewContext(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, keyID)
+}
+
+// FromContext returns the ID of the API key of the caller stored in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(contextKey{}).(string)
+	return keyID, ok && keyID != ""
+}
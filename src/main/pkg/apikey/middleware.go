@@ -0,0 +1,61 @@
+package apikey
+
+import (
+	"context"
+
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+	"order-management-ms/src/main/services/apikeys"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Authenticator checks the API keys sent by the clients
+type Authenticator interface {
+	Authenticate(ctx context.Context, plainKey, clientIP string) (*domain.APIKey, error)
+}
+
+// Middleware authenticates the requests sending an API key in the header, binding them to the
+// tenant and the scopes of the key. Requests without an API key are left to next, the
+// authentication of the other callers, or rejected when next is nil.
+func Middleware(header string, authenticator Authenticator, next gin.HandlerFunc, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plainKey := c.GetHeader(header)
+		if plainKey == "" {
+			if next != nil {
+				next(c)
+				return
+			}
+			_ = c.Error(customerrors.ErrMissingAPIKey)
+			c.Abort()
+			return
+		}
+
+		ctx, err := Authenticate(c.Request.Context(), authenticator, plainKey, c.ClientIP())
+		if err != nil {
+			logger.Debug("Rejected API key", zap.Error(err), zap.String("path", c.Request.URL.Path))
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Authenticate checks the API key sent from clientIP and returns a copy of ctx bound to the
+// tenant, the scopes and the ID of the key
+func Authenticate(ctx context.Context, authenticator Authenticator, plainKey, clientIP string) (context.Context, error) {
+	key, err := authenticator.Authenticate(ctx, plainKey, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = tenant.NewContext(ctx, key.TenantID)
+	ctx = authz.NewContext(ctx, authz.NewAccess("", apikeys.Permissions(key)))
+	return NewContext(ctx, key.KeyID), nil
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims of the caller, the access granted by
+// the policy to their roles and the tenant they are bound to
+func NewContext(ctx context.Context, claims *Claims, policy authz.Policy) context.Context {
+	ctx = authz.NewContext(ctx, policy.Access(claims.Customer(), claims.Roles))
+	if claims.TenantID != "" {
+		ctx = tenant.NewContext(ctx, claims.TenantID)
+	}
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims of the caller stored in ctx, if any
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// Subject returns the subject of the caller stored in ctx, if any
+func Subject(ctx context.Context) (string, bool) {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return claims.Subject, true
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval limits how often the key set is reloaded on the request path, whether the
+// previous attempt succeeded or not
+const minRefreshInterval = 30 * time.Second
+
+// errKeyNotFound is returned when no key of the key set can verify a token
+var errKeyNotFound = errors.New("no matching key in the key set")
+
+// KeySet holds the verification keys of a JSON Web Key Set (RFC 7517) loaded from a file or
+// an http(s) URL. It is reloaded in the background after the refresh interval, when set, and
+// when a token is signed with an unknown key, so keys can be rotated without a restart. Reloads
+// are shared by the concurrent requests and attempted at most every 30 seconds.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	reloads singleflight.Group
+
+	mu          sync.RWMutex
+	keys        []jsonWebKey
+	loadedAt    time.Time
+	attemptedAt time.Time
+}
+
+// jsonWebKey is a parsed JWK. Key is a *rsa.PublicKey for RSA keys and a []byte secret for
+// symmetric keys.
+type jsonWebKey struct {
+	ID        string
+	Algorithm string
+	Key       interface{}
+}
+
+// rawJSONWebKey is a JWK as found in the key set document
+type rawJSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// NewKeySet loads the key set at source, a file path or an http(s) URL
+func NewKeySet(ctx context.Context, source string, refresh time.Duration) (*KeySet, error) {
+	ks := &KeySet{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := ks.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Reload reads the key set again from its source
+func (ks *KeySet) Reload(ctx context.Context) error {
+	ks.mu.Lock()
+	ks.attemptedAt = time.Now()
+	ks.mu.Unlock()
+
+	data, err := ks.read(ctx)
+	if err != nil {
+		return fmt.Errorf("read key set %s: %w", ks.source, err)
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return fmt.Errorf("parse key set %s: %w", ks.source, err)
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+// Key returns the key with the given ID that verifies the algorithm. Tokens without a key
+// ID are verified with the only key of the algorithm, if there is exactly one.
+func (ks *KeySet) Key(ctx context.Context, kid, alg string) (interface{}, error) {
+	ks.mu.RLock()
+	key, err := findKey(ks.keys, kid, alg)
+	age := time.Since(ks.loadedAt)
+	throttled := time.Since(ks.attemptedAt) < minRefreshInterval
+	ks.mu.RUnlock()
+
+	switch {
+	case throttled:
+		return key, err
+	case err != nil:
+		// Unknown keys may have been added by a rotation, so the request waits for the
+		// reload. The previous keys are kept when it fails.
+		select {
+		case result := <-ks.reload():
+			if result.Err == nil {
+				ks.mu.RLock()
+				key, err = findKey(ks.keys, kid, alg)
+				ks.mu.RUnlock()
+			}
+		case <-ctx.Done():
+		}
+	case ks.refresh > 0 && age > ks.refresh:
+		// Stale keys still verify the request while they are refreshed
+		ks.reload()
+	}
+	return key, err
+}
+
+// reload starts a reload of the key set, unless one is running, and returns the channel
+// receiving its result. It is not bound to the request that started it, as every waiting
+// request shares it.
+func (ks *KeySet) reload() <-chan singleflight.Result {
+	return ks.reloads.DoChan(ks.source, func() (interface{}, error) {
+		return nil, ks.Reload(context.Background())
+	})
+}
+
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseKeySet parses the RSA and symmetric signing keys of a key set, skipping the others
+func parseKeySet(data []byte) ([]jsonWebKey, error) {
+	var doc struct {
+		Keys []rawJSONWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var keys []jsonWebKey
+	for _, raw := range doc.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		switch raw.Kty {
+		case "RSA":
+			key, err := parseRSAKey(raw)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", raw.Kid, err)
+			}
+			keys = append(keys, jsonWebKey{ID: raw.Kid, Algorithm: algorithmOr(raw.Alg, "RS256"), Key: key})
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(raw.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("key %q: invalid secret", raw.Kid)
+			}
+			keys = append(keys, jsonWebKey{ID: raw.Kid, Algorithm: algorithmOr(raw.Alg, "HS256"), Key: secret})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(raw rawJSONWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(raw.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(raw.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func findKey(keys []jsonWebKey, kid, alg string) (interface{}, error) {
+	var match interface{}
+	matches := 0
+	for _, key := range keys {
+		if key.Algorithm != alg {
+			continue
+		}
+		if kid != "" && key.ID == kid {
+			return key.Key, nil
+		}
+		match = key.Key
+		matches++
+	}
+
+	if kid == "" && matches == 1 {
+		return match, nil
+	}
+	return nil, errKeyNotFound
+}
+
+func algorithmOr(alg, fallback string) string {
+	if alg == "" {
+		return fallback
+	}
+	return alg
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"order-management-ms/src/main/pkg/authz"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Middleware authenticates the requests with the bearer token of the Authorization header
+// and stores the claims of the caller, the access the policy grants to their roles and the
+// tenant they are bound to in the request context
+func Middleware(verifier *Verifier, policy authz.Policy, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request.Context(), c.GetHeader("Authorization"))
+		if err != nil {
+			logger.Debug("Rejected unauthenticated request", zap.Error(err), zap.String("path", c.Request.URL.Path))
+			c.Header("WWW-Authenticate", `Bearer realm="order-management-ms"`)
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), claims, policy))
+		c.Next()
+	}
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"order-management-ms/src/main/config"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods are the accepted token algorithms. Keys are looked up by algorithm, so a
+// token can never be verified with a key of another kind.
+var signingMethods = []string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}
+
+// Claims are the claims of a verified token
+type Claims struct {
+	jwt.RegisteredClaims
+	CustomerID string   `json:"customer_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	// TenantID is the tenant the caller belongs to, read from the configured tenant claim
+	TenantID string `json:"-"`
+}
+
+// Customer returns the customer the caller acts for: the customer_id claim, or the subject
+// when the token has none
+func (c *Claims) Customer() string {
+	if c.CustomerID != "" {
+		return c.CustomerID
+	}
+	return c.Subject
+}
+
+// tokenClaims decodes the claims of a token, reading the tenant from the configured claim
+type tokenClaims struct {
+	*Claims
+	tenantClaim string
+}
+
+func (c *tokenClaims) UnmarshalJSON(data []byte) error {
+	type plainClaims Claims
+	if err := json.Unmarshal(data, (*plainClaims)(c.Claims)); err != nil {
+		return err
+	}
+
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	if raw, ok := extra[c.tenantClaim]; ok {
+		if err := json.Unmarshal(raw, &c.TenantID); err != nil {
+			return fmt.Errorf("claim %s: %w", c.tenantClaim, err)
+		}
+	}
+	return nil
+}
+
+// Verifier verifies bearer tokens against a key set, checking their issuer, audience, expiry
+// and tenant
+type Verifier struct {
+	keys          *KeySet
+	parser        *jwt.Parser
+	tenantClaim   string
+	defaultTenant string
+}
+
+// NewVerifier creates a verifier for the tokens signed with the keys. The issuer and the
+// audience are only checked when configured. Tokens without a tenant claim are bound to
+// defaultTenant, and rejected when there is none.
+func NewVerifier(keys *KeySet, cfg config.Auth, defaultTenant string) *Verifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	tenantClaim := cfg.TenantClaim
+	if tenantClaim == "" {
+		tenantClaim = "tenant_id"
+	}
+
+	return &Verifier{
+		keys:          keys,
+		parser:        jwt.NewParser(options...),
+		tenantClaim:   tenantClaim,
+		defaultTenant: defaultTenant,
+	}
+}
+
+// Authenticate verifies the token of an Authorization header value, "Bearer <token>"
+func (v *Verifier) Authenticate(ctx context.Context, authorization string) (*Claims, error) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, errors.ErrMissingAuthToken
+	}
+
+	claims, err := v.Verify(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidAuthToken, err)
+	}
+	return claims, nil
+}
+
+// Verify checks the signature and the claims of a token
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, &tokenClaims{Claims: claims, tenantClaim: v.tenantClaim}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid, t.Method.Alg())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	if claims.TenantID == "" {
+		claims.TenantID = v.defaultTenant
+	}
+	switch {
+	case claims.TenantID == "":
+		return nil, fmt.Errorf("token has no %s claim", v.tenantClaim)
+	case !tenant.IsValidID(claims.TenantID):
+		return nil, fmt.Errorf("token has an invalid %s claim", v.tenantClaim)
+	}
+	return claims, nil
+}
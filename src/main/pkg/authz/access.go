@@ -0,0 +1,74 @@
+package authz
+
+import (
+	"context"
+
+	errors "order-management-ms/src/main/pkg/customerrors"
+)
+
+type contextKey struct{}
+
+// Access is what an authenticated caller is allowed to do
+type Access struct {
+	CustomerID  string
+	permissions map[Permission]bool
+}
+
+// NewAccess returns the access of a caller acting for customerID with the permissions
+func NewAccess(customerID string, permissions []Permission) *Access {
+	access := &Access{CustomerID: customerID, permissions: map[Permission]bool{}}
+	for _, permission := range permissions {
+		access.permissions[permission] = true
+	}
+	return access
+}
+
+// Can checks if the caller was granted the permission
+func (a *Access) Can(permission Permission) bool {
+	return a.permissions[permission]
+}
+
+// NewContext returns a copy of ctx carrying the access of the caller
+func NewContext(ctx context.Context, access *Access) context.Context {
+	return context.WithValue(ctx, contextKey{}, access)
+}
+
+// FromContext returns the access of the caller stored in ctx, if any. Requests without
+// access, like the ones served while authentication is disabled, are not restricted.
+func FromContext(ctx context.Context) (*Access, bool) {
+	access, ok := ctx.Value(contextKey{}).(*Access)
+	return access, ok && access != nil
+}
+
+// ReadScope returns the customer whose orders the caller may read, or "" when the caller may
+// read the orders of every customer
+func ReadScope(ctx context.Context) (string, error) {
+	return scope(ctx, ReadOrders, ReadOwnOrders)
+}
+
+// WriteScope returns the customer whose orders the caller may create, or "" when the caller
+// may create orders for every customer
+func WriteScope(ctx context.Context) (string, error) {
+	return scope(ctx, WriteOrders, WriteOwnOrders)
+}
+
+// Require fails with ErrForbidden unless the caller was granted the permission
+func Require(ctx context.Context, permission Permission) error {
+	access, ok := FromContext(ctx)
+	if !ok || access.Can(permission) {
+		return nil
+	}
+	return errors.ErrForbidden
+}
+
+func scope(ctx context.Context, all, own Permission) (string, error) {
+	access, ok := FromContext(ctx)
+	switch {
+	case !ok || access.Can(all):
+		return "", nil
+	case access.Can(own) && access.CustomerID != "":
+		return access.CustomerID, nil
+	default:
+		return "", errors.ErrForbidden
+	}
+}
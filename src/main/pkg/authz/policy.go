@@ -0,0 +1,96 @@
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Permission is an operation on orders that a role may be granted
+type Permission string
+
+// Permissions of the order operations. The ":own" permissions are limited to the orders of
+// the caller's own customer.
+const (
+	ReadOwnOrders  Permission = "orders:read:own"
+	ReadOrders     Permission = "orders:read"
+	WriteOwnOrders Permission = "orders:write:own"
+	WriteOrders    Permission = "orders:write"
+	UpdateStatus   Permission = "orders:status"
+	DeliverOrders  Permission = "orders:deliver"
+	ManageAPIKeys  Permission = "apikeys:manage"
+	ManageWebhooks Permission = "webhooks:manage"
+	// AllTenants lets a caller bound to a tenant act on another one sent in the tenant header.
+	// No built-in role grants it.
+	AllTenants Permission = "tenants:all"
+)
+
+// APIKeyScopes are the permissions that can be granted to API keys
+var APIKeyScopes = []Permission{ReadOrders, WriteOrders, UpdateStatus}
+
+var knownPermissions = map[Permission]bool{
+	ReadOwnOrders:  true,
+	ReadOrders:     true,
+	WriteOwnOrders: true,
+	WriteOrders:    true,
+	UpdateStatus:   true,
+	DeliverOrders:  true,
+	ManageAPIKeys:  true,
+	ManageWebhooks: true,
+	AllTenants:     true,
+}
+
+// Policy maps each role to the permissions it grants
+type Policy map[string][]Permission
+
+// DefaultPolicy returns the policy used when no policy file is configured: customers manage
+// their own orders, operators manage every order, only fulfillment delivers them and only
+// admins manage the API keys and the webhooks
+func DefaultPolicy() Policy {
+	return Policy{
+		"customer":    {ReadOwnOrders, WriteOwnOrders},
+		"operator":    {ReadOrders, WriteOrders, UpdateStatus},
+		"fulfillment": {ReadOrders, UpdateStatus, DeliverOrders},
+		"admin":       {ReadOrders, WriteOrders, UpdateStatus, ManageAPIKeys, ManageWebhooks},
+	}
+}
+
+// LoadPolicy reads a policy from a JSON file mapping roles to permissions, e.g.
+// {"customer": ["orders:read:own", "orders:write:own"]}
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy %s: %w", path, err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks that the policy only grants known permissions
+func (p Policy) Validate() error {
+	for role, permissions := range p {
+		for _, permission := range permissions {
+			if !knownPermissions[permission] {
+				return fmt.Errorf("role %q grants unknown permission %q", role, permission)
+			}
+		}
+	}
+	return nil
+}
+
+// Access returns the access granted by the policy to a caller with the given roles, acting
+// for customerID. Unknown roles grant nothing.
+func (p Policy) Access(customerID string, roles []string) *Access {
+	var permissions []Permission
+	for _, role := range roles {
+		permissions = append(permissions, p[role]...)
+	}
+	return NewAccess(customerID, permissions)
+}
@@ -18,21 +18,59 @@ var (
 	ErrInvalidTransition  = &apiError{status: http.StatusBadRequest, code: "INVALID_TRANSITION", message: "invalid status transition"}
 	ErrInvalidPage        = &apiError{status: http.StatusBadRequest, code: "INVALID_PAGE", message: "invalid page number"}
 	ErrInvalidLimit       = &apiError{status: http.StatusBadRequest, code: "INVALID_LIMIT", message: "invalid limit value"}
+	ErrInvalidCursor      = &apiError{status: http.StatusBadRequest, code: "INVALID_CURSOR", message: "invalid cursor"}
+	ErrInvalidDateFilter  = &apiError{status: http.StatusBadRequest, code: "INVALID_DATE_FILTER", message: "invalid date filter, expected RFC 3339 or YYYY-MM-DD"}
+	ErrInvalidTotalFilter = &apiError{status: http.StatusBadRequest, code: "INVALID_TOTAL_FILTER", message: "invalid order total filter"}
+	ErrInvalidSort        = &apiError{status: http.StatusBadRequest, code: "INVALID_SORT", message: "invalid sort, allowed fields are created_at, updated_at, status, total and customer_id"}
+	ErrUnsupportedSort    = &apiError{status: http.StatusBadRequest, code: "UNSUPPORTED_SORT", message: "sort combination is not supported by an index"}
+	ErrCursorSort         = &apiError{status: http.StatusBadRequest, code: "CURSOR_SORT", message: "cursor pagination only supports the default sort"}
+	ErrInvalidFields      = &apiError{status: http.StatusBadRequest, code: "INVALID_FIELDS", message: "invalid fields, allowed fields are order_id, customer_id, customer_name, shipping_address, status, items, total, created_at, updated_at and archived_at"}
+	ErrInvalidExpand      = &apiError{status: http.StatusBadRequest, code: "INVALID_EXPAND", message: "invalid expand, allowed resources are history"}
+	ErrInvalidSearchQuery = &apiError{status: http.StatusBadRequest, code: "INVALID_SEARCH_QUERY", message: "search query must have at least 2 characters"}
+	ErrInvalidEventID     = &apiError{status: http.StatusBadRequest, code: "INVALID_EVENT_ID", message: "invalid last event ID"}
+	ErrTooManyOrders      = &apiError{status: http.StatusBadRequest, code: "TOO_MANY_ORDERS", message: "too many orders subscribed"}
+	ErrInvalidFormat      = &apiError{status: http.StatusBadRequest, code: "INVALID_FORMAT", message: "invalid export format, allowed formats are csv and ndjson"}
+
+	// 400 Bad Request - Webhook related
+	ErrInvalidWebhookURL     = &apiError{status: http.StatusBadRequest, code: "INVALID_WEBHOOK_URL", message: "invalid webhook URL, expected an absolute HTTPS URL"}
+	ErrInvalidDeliveryStatus = &apiError{status: http.StatusBadRequest, code: "INVALID_DELIVERY_STATUS", message: "invalid delivery status, allowed statuses are PENDING, SUCCEEDED and DEAD_LETTER"}
+
+	// 400 Bad Request - Tenant related
+	ErrMissingTenant = &apiError{status: http.StatusBadRequest, code: "MISSING_TENANT", message: "missing tenant identifier"}
+	ErrInvalidTenant = &apiError{status: http.StatusBadRequest, code: "INVALID_TENANT", message: "invalid tenant identifier"}
+
 	// 401 Unauthorized
 	ErrMissingAuthToken = &apiError{status: http.StatusUnauthorized, code: "MISSING_AUTH_TOKEN", message: "missing authorization token"}
 	ErrInvalidAuthToken = &apiError{status: http.StatusUnauthorized, code: "INVALID_AUTH_TOKEN", message: "invalid or expired authorization token"}
+	ErrInvalidAPIKey    = &apiError{status: http.StatusUnauthorized, code: "INVALID_API_KEY", message: "invalid or revoked API key"}
+	ErrMissingAPIKey    = &apiError{status: http.StatusUnauthorized, code: "MISSING_API_KEY", message: "missing API key"}
+
+	// 403 Forbidden
+	ErrForbidden    = &apiError{status: http.StatusForbidden, code: "FORBIDDEN", message: "insufficient permissions"}
+	ErrIPNotAllowed = &apiError{status: http.StatusForbidden, code: "IP_NOT_ALLOWED", message: "API key is not allowed from this IP address"}
 
 	// 404 Not Found
-	ErrOrderNotFound = &apiError{status: http.StatusNotFound, code: "ORDER_NOT_FOUND", message: "order not found"}
+	ErrOrderNotFound    = &apiError{status: http.StatusNotFound, code: "ORDER_NOT_FOUND", message: "order not found"}
+	ErrAPIKeyNotFound   = &apiError{status: http.StatusNotFound, code: "API_KEY_NOT_FOUND", message: "API key not found"}
+	ErrWebhookNotFound  = &apiError{status: http.StatusNotFound, code: "WEBHOOK_NOT_FOUND", message: "webhook not found"}
+	ErrDeliveryNotFound = &apiError{status: http.StatusNotFound, code: "DELIVERY_NOT_FOUND", message: "webhook delivery not found"}
+
+	// 405 Method Not Allowed
+	ErrMutationRequiresPost = &apiError{status: http.StatusMethodNotAllowed, code: "METHOD_NOT_ALLOWED", message: "mutations require POST"}
 
 	// 409 Conflict
 	ErrOrderAlreadyExists = &apiError{status: http.StatusConflict, code: "ORDER_ALREADY_EXISTS", message: "order already exists"}
 
+	// 429 Too Many Requests
+	ErrRateLimited = &apiError{status: http.StatusTooManyRequests, code: "RATE_LIMITED", message: "too many requests"}
+
 	// 500 Internal Server Error
 	ErrInternalServer = &apiError{status: http.StatusInternalServerError, code: "INTERNAL_SERVER_ERROR", message: "internal server error"}
 
-	ErrFailedToCreateOrder = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_CREATE_ORDER", message: "failed to create order"}
-	ErrFailedToUpdateOrder = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_UPDATE_ORDER", message: "failed to update order"}
+	ErrFailedToCreateOrder   = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_CREATE_ORDER", message: "failed to create order"}
+	ErrFailedToUpdateOrder   = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_UPDATE_ORDER", message: "failed to update order"}
+	ErrFailedToIssueAPIKey   = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_ISSUE_API_KEY", message: "failed to issue API key"}
+	ErrFailedToCreateWebhook = &apiError{status: http.StatusInternalServerError, code: "FAILED_TO_CREATE_WEBHOOK", message: "failed to create webhook"}
 )
 
 // apiError implements the Error interface
@@ -1,41 +1,57 @@
 package customerrors
 
 import (
-	"net/http"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ErrorResponse is the standard error response format
+// ErrorResponse is the legacy error response format, still served to the clients that
+// accept application/json but not application/problem+json
 type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
-// ErrorHandler is a middleware that handles API errors
+// ErrorHandler is a middleware that handles API errors. Handlers report errors with
+// ctx.Error; the last one is written with the status and code of the API error it wraps, or
+// as an internal server error when it wraps none. Errors are rendered as problem documents
+// unless the Accept header prefers application/json, which gets the legacy ErrorResponse.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		// Check for errors
-		if len(c.Errors) > 0 {
-			err := c.Errors.Last().Err
-
-			switch e := err.(type) {
-			case Error:
-				c.JSON(e.StatusCode(), ErrorResponse{
-					Code:    e.ErrorCode(),
-					Message: e.Error(),
-				})
-			case *ValidationErrorResponse:
-				c.JSON(e.StatusCode(), e)
-			default:
-				c.JSON(http.StatusInternalServerError, ErrorResponse{
-					Code:    "INTERNAL_SERVER_ERROR",
-					Message: "An unexpected error occurred",
-				})
-			}
+		if len(c.Errors) == 0 || c.Writer.Written() {
 			return
 		}
+		err := c.Errors.Last().Err
+
+		if c.NegotiateFormat(MIMEProblemJSON, gin.MIMEJSON) == gin.MIMEJSON {
+			writeErrorResponse(c, err)
+			return
+		}
+
+		problem := NewProblem(err, c.Request.URL.RequestURI())
+		c.Header("Content-Type", MIMEProblemJSON)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// writeErrorResponse writes err in the legacy format
+func writeErrorResponse(c *gin.Context, err error) {
+	var validationErr *ValidationErrorResponse
+	if errors.As(err, &validationErr) {
+		c.JSON(validationErr.StatusCode(), validationErr)
+		return
+	}
+
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		apiErr = ErrInternalServer
 	}
+	c.JSON(apiErr.StatusCode(), ErrorResponse{
+		Code:    apiErr.ErrorCode(),
+		Message: apiErr.Error(),
+	})
 }
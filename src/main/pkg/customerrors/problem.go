@@ -0,0 +1,50 @@
+package customerrors
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// MIMEProblemJSON is the media type of the RFC 7807 problem documents
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemTypeBaseURI prefixes the type URI of the problems, followed by the error code in
+// kebab case, e.g. https://order-management-ms/problems/order-not-found
+var ProblemTypeBaseURI = "https://order-management-ms/problems/"
+
+// ProblemDetails is the RFC 7807 problem document of an error. Code and Errors are
+// extension members holding the error code and the failing fields of validation errors.
+type ProblemDetails struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   []ValidationError `json:"errors,omitempty"`
+}
+
+// NewProblem builds the problem document of err for the request URI in instance. Errors
+// that do not wrap an API error are reported as internal server errors.
+func NewProblem(err error, instance string) *ProblemDetails {
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		apiErr = ErrInternalServer
+	}
+
+	problem := &ProblemDetails{
+		Type:     ProblemTypeBaseURI + strings.ReplaceAll(strings.ToLower(apiErr.ErrorCode()), "_", "-"),
+		Title:    http.StatusText(apiErr.StatusCode()),
+		Status:   apiErr.StatusCode(),
+		Detail:   apiErr.Error(),
+		Instance: instance,
+		Code:     apiErr.ErrorCode(),
+	}
+
+	var validationErr *ValidationErrorResponse
+	if errors.As(err, &validationErr) {
+		problem.Errors = validationErr.Errors
+	}
+	return problem
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+)
+
+// StatusChangedType is the type of the order status change events sent to the subscribers
+const StatusChangedType = "order.status_changed"
+
+// validID matches the IDs of the Redis stream entries, which are also the IDs of the events
+var validID = regexp.MustCompile(`^[0-9]{1,20}-[0-9]{1,20}$`)
+
+// Event is an order status change read from the stream. Its ID orders it among the other
+// events and lets a subscriber resume after it.
+type Event struct {
+	ID       string `json:"-"`
+	TenantID string `json:"-"`
+	kafkaDto.OrderStatusChangedEvent
+}
+
+// Filter selects the events of a tenant, optionally of some orders or of a customer, or with
+// one of the given new statuses
+type Filter struct {
+	TenantID   string
+	OrderIDs   []string
+	CustomerID string
+	Statuses   []datastore.OrderStatus
+}
+
+// Matches checks if the event is selected by the filter
+func (f Filter) Matches(event Event) bool {
+	if event.TenantID != f.TenantID {
+		return false
+	}
+	if len(f.OrderIDs) > 0 && !slices.Contains(f.OrderIDs, event.OrderID) {
+		return false
+	}
+	if f.CustomerID != "" && event.CustomerID != f.CustomerID {
+		return false
+	}
+	return len(f.Statuses) == 0 || slices.Contains(f.Statuses, event.NewStatus)
+}
+
+// IsValidID checks if an event ID, such as the one sent to resume a stream, has a valid format
+func IsValidID(id string) bool {
+	return validID.MatchString(id)
+}
+
+// IsAfter checks if the event with ID id was appended after the one with ID other. An empty
+// other comes before every event.
+func IsAfter(id, other string) bool {
+	if other == "" {
+		return true
+	}
+	ms, seq := splitID(id)
+	otherMs, otherSeq := splitID(other)
+	return ms > otherMs || (ms == otherMs && seq > otherSeq)
+}
+
+// splitID splits an event ID into its milliseconds and sequence number
+func splitID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	kafkaDto "order-management-ms/src/main/models/kafka"
+)
+
+// Publishers publishes the events to every publisher, like the Kafka topic and the stream of
+// the live subscribers
+type Publishers []kafkaDto.EventPublisher
+
+// Ensure Publishers implements kafkaDto.EventPublisher
+var _ kafkaDto.EventPublisher = Publishers(nil)
+
+// PublishOrderStatusChanged publishes the event to every publisher, even when some of them
+// fail, and returns their errors
+func (p Publishers) PublishOrderStatusChanged(ctx context.Context, event kafkaDto.OrderStatusChangedEvent) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.PublishOrderStatusChanged(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
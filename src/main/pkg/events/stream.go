@@ -0,0 +1,257 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"order-management-ms/src/main/config"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// blockTimeout bounds each blocking read of the stream, so the reader notices it was stopped
+	blockTimeout = 5 * time.Second
+	// retryDelay is the pause before reading the stream again after a failure
+	retryDelay = time.Second
+	// readCount is the maximum number of entries read from the stream at once
+	readCount = 100
+)
+
+// Stream appends the order status changes to a Redis stream and pushes the ones appended by
+// every instance to its local subscribers
+type Stream struct {
+	client redis.Cmdable
+	key    string
+	maxLen int64
+	buffer int
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// Ensure Stream implements kafkaDto.EventPublisher
+var _ kafkaDto.EventPublisher = (*Stream)(nil)
+
+// NewStream creates a stream of the order status changes stored in Redis
+func NewStream(client redis.Cmdable, cfg config.Events, logger *zap.Logger) *Stream {
+	return &Stream{
+		client:      client,
+		key:         cfg.Stream,
+		maxLen:      cfg.StreamMaxLen,
+		buffer:      max(cfg.Buffer, 1),
+		logger:      logger,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Subscription receives the events matching its filter
+type Subscription struct {
+	filter Filter
+	events chan Event
+}
+
+// Events returns the channel of the events. It is closed when the subscriber falls behind by
+// more than the buffer of the stream, or when the stream stops.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// PublishOrderStatusChanged appends the event to the stream, with the tenant stored in ctx
+func (s *Stream) PublishOrderStatusChanged(ctx context.Context, event kafkaDto.OrderStatusChangedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+
+	err = s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.key,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: []interface{}{"tenant_id", tenantID, "data", data},
+	}).Err()
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to append event to the stream", zap.Error(err), zap.String("order_id", event.OrderID))
+	}
+	return err
+}
+
+// Replay returns the events matching the filter that were appended after the event afterID,
+// oldest first and at most limit of them. Events trimmed from the stream are not returned.
+func (s *Stream) Replay(ctx context.Context, filter Filter, afterID string, limit int64) ([]Event, error) {
+	events := []Event{}
+	for int64(len(events)) < limit {
+		messages, err := s.client.XRangeN(ctx, s.key, "("+afterID, "+", readCount).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			afterID = message.ID
+			if event, ok := s.decode(message); ok && filter.Matches(event) {
+				events = append(events, event)
+				if int64(len(events)) == limit {
+					break
+				}
+			}
+		}
+		if len(messages) < readCount {
+			break
+		}
+	}
+	return events, nil
+}
+
+// Subscribe subscribes to the events matching the filter until Unsubscribe is called
+func (s *Stream) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{filter: filter, events: make(chan Event, s.buffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(sub.events)
+		return sub
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// SetFilter replaces the filter of a subscription. Events already pushed to it are kept.
+func (s *Stream) SetFilter(sub *Subscription, filter Filter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub.filter = filter
+}
+
+// Unsubscribe stops pushing events to the subscription and closes its channel
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+// Close closes every subscription, and the ones made afterwards, so their subscribers stop
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subscribers {
+		s.remove(sub)
+	}
+}
+
+// Run reads the events appended to the stream by every instance and pushes them to the
+// subscribers until ctx is done, then closes the stream. Read failures, like Redis being
+// unavailable, are retried from the last event read, so no event is skipped.
+func (s *Stream) Run(ctx context.Context) {
+	defer s.Close()
+
+	var lastID string
+	for ctx.Err() == nil {
+		if lastID == "" {
+			id, err := s.lastID(ctx)
+			if err != nil {
+				s.retry(ctx, err)
+				continue
+			}
+			lastID = id
+		}
+
+		streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{s.key, lastID},
+			Count:   readCount,
+			Block:   blockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			s.retry(ctx, err)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				lastID = message.ID
+				if event, ok := s.decode(message); ok {
+					s.broadcast(event)
+				}
+			}
+		}
+	}
+}
+
+// lastID returns the ID of the last event of the stream, or 0-0 when it is empty, so only
+// the events appended afterwards are read
+func (s *Stream) lastID(ctx context.Context) (string, error) {
+	messages, err := s.client.XRevRangeN(ctx, s.key, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+	return messages[0].ID, nil
+}
+
+// retry logs a read failure and waits before the next read, unless ctx is done
+func (s *Stream) retry(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	s.logger.Warn("Failed to read the event stream", zap.Error(err), zap.String("stream", s.key))
+
+	timer := time.NewTimer(retryDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// broadcast pushes the event to the matching subscribers. Subscribers whose buffer is full
+// are dropped rather than slowing the others down; they resume from their last event.
+func (s *Stream) broadcast(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.logger.Warn("Dropping slow event subscriber", zap.String("event_id", event.ID))
+			s.remove(sub)
+		}
+	}
+}
+
+// remove closes a subscription, if it is still open. The caller must hold the lock.
+func (s *Stream) remove(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// decode reads the event of a stream entry, skipping the malformed ones
+func (s *Stream) decode(message redis.XMessage) (Event, bool) {
+	event := Event{ID: message.ID}
+	event.TenantID, _ = message.Values["tenant_id"].(string)
+	data, _ := message.Values["data"].(string)
+	if err := json.Unmarshal([]byte(data), &event.OrderStatusChangedEvent); err != nil {
+		s.logger.Warn("Skipping malformed event", zap.Error(err), zap.String("event_id", message.ID))
+		return Event{}, false
+	}
+	return event, true
+}
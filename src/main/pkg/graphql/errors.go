@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"errors"
+
+	"order-management-ms/src/main/pkg/customerrors"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// resolverError exposes the code of an API error in the extensions of a GraphQL error
+type resolverError struct {
+	err customerrors.Error
+}
+
+func (e resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e resolverError) Unwrap() error {
+	return e.err
+}
+
+// Extensions implements gqlerrors.ExtendedError, listing the failing fields of validation errors
+func (e resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.err.ErrorCode()}
+
+	var validationErr *customerrors.ValidationErrorResponse
+	if errors.As(e.err, &validationErr) && len(validationErr.Errors) > 0 {
+		extensions["errors"] = validationErr.Errors
+	}
+	return extensions
+}
+
+// toResolverError keeps the message and code of API errors and hides any other error
+// behind an internal server error
+func toResolverError(err error) error {
+	var apiErr customerrors.Error
+	if errors.As(err, &apiErr) {
+		return resolverError{apiErr}
+	}
+	return resolverError{customerrors.ErrInternalServer}
+}
+
+// ExtendErrors sets the extensions of the errors raised by thunks. The executor formats
+// those errors before locating them, which drops their extensions, so they are recovered
+// from the chain of original errors.
+func ExtendErrors(result *gql.Result) {
+	for i, formatted := range result.Errors {
+		if formatted.Extensions != nil {
+			continue
+		}
+		if extended := findExtendedError(formatted.OriginalError()); extended != nil {
+			result.Errors[i].Extensions = extended.Extensions()
+		}
+	}
+}
+
+func findExtendedError(err error) gqlerrors.ExtendedError {
+	for err != nil {
+		if extended, ok := err.(gqlerrors.ExtendedError); ok {
+			return extended
+		}
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			err = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			err = wrapped.OriginalError
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+	return nil
+}
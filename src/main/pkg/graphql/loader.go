@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc fetches the values of a batch of keys in a single call. Keys missing from the
+// result resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys requested while a level of the query is resolved and fetches
+// them with one BatchFunc call when the first value is needed. Results are memoized for the
+// lifetime of the loader, which is a single request.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loadResult[V]
+}
+
+type loadResult[V any] struct {
+	done  bool
+	value V
+	err   error
+}
+
+// NewLoader creates a loader fetching its values with the batch function
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:   batch,
+		results: make(map[K]*loadResult[V]),
+	}
+}
+
+// Load queues a key and returns a thunk resolving its value. The batch only runs when a
+// thunk is called, so every key queued before then shares the same call.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = &loadResult[V]{}
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		result := l.results[key]
+		if !result.done {
+			l.dispatch(ctx)
+		}
+		return result.value, result.err
+	}
+}
+
+// dispatch fetches every pending key, must be called with the lock held
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		result := l.results[key]
+		result.done = true
+		result.value = values[key]
+		result.err = err
+	}
+}
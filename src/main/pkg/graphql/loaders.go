@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"context"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/services/orders"
+)
+
+// orderRead reads orders with their status history so every field of the schema is available
+var orderRead = domain.ReadOptions{Expand: []string{domain.ExpandHistory}}
+
+// Loaders batches the repository calls made while resolving a request
+type Loaders struct {
+	Orders    *Loader[string, *models.OrderResponse]
+	Customers *Loader[string, *models.CustomerSummaryResponse]
+}
+
+type loadersKey struct{}
+
+// NewLoaders creates the loaders of a request
+func NewLoaders(service orders.Service) *Loaders {
+	return &Loaders{
+		Orders: NewLoader(func(ctx context.Context, orderIDs []string) (map[string]*models.OrderResponse, error) {
+			found, err := service.GetOrdersByIDs(ctx, orderIDs, orderRead)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*models.OrderResponse, len(found))
+			for _, order := range found {
+				byID[order.OrderID] = order
+			}
+			return byID, nil
+		}),
+		Customers: NewLoader(func(ctx context.Context, customerIDs []string) (map[string]*models.CustomerSummaryResponse, error) {
+			found, err := service.GetCustomerSummaries(ctx, customerIDs)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*models.CustomerSummaryResponse, len(found))
+			for _, summary := range found {
+				byID[summary.CustomerID] = summary
+			}
+			return byID, nil
+		}),
+	}
+}
+
+// WithLoaders returns a copy of the context carrying the loaders
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFrom returns the loaders carried by the context
+func loadersFrom(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return loaders
+}
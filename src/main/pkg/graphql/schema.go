@@ -0,0 +1,371 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/validation"
+	"order-management-ms/src/main/services/orders"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// Default and maximum page sizes of the orders query, the same as the REST listing
+const (
+	defaultFirst = 10
+	maxFirst     = 100
+)
+
+var orderStatusEnum = gql.NewEnum(gql.EnumConfig{
+	Name: "OrderStatus",
+	Values: gql.EnumValueConfigMap{
+		string(domain.StatusNew):        {Value: string(domain.StatusNew)},
+		string(domain.StatusInProgress): {Value: string(domain.StatusInProgress)},
+		string(domain.StatusDelivered):  {Value: string(domain.StatusDelivered)},
+		string(domain.StatusCancelled):  {Value: string(domain.StatusCancelled)},
+	},
+})
+
+var itemType = gql.NewObject(gql.ObjectConfig{
+	Name: "Item",
+	Fields: gql.Fields{
+		"sku": {Type: gql.NewNonNull(gql.String), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.Items).Sku, nil
+		}},
+		"quantity": {Type: gql.NewNonNull(gql.Int), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.Items).Quantity, nil
+		}},
+		"price": {Type: gql.NewNonNull(gql.Float), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.Items).Price, nil
+		}},
+	},
+})
+
+var statusChangeType = gql.NewObject(gql.ObjectConfig{
+	Name: "StatusChange",
+	Fields: gql.Fields{
+		"status": {Type: gql.NewNonNull(orderStatusEnum), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.StatusChangeResponse).Status, nil
+		}},
+		"changedAt": {Type: gql.NewNonNull(gql.DateTime), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.StatusChangeResponse).ChangedAt, nil
+		}},
+	},
+})
+
+var customerSummaryType = gql.NewObject(gql.ObjectConfig{
+	Name:        "CustomerSummary",
+	Description: "Order statistics of a customer",
+	Fields: gql.Fields{
+		"customerId": {Type: gql.NewNonNull(gql.ID), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.CustomerSummaryResponse).CustomerID, nil
+		}},
+		"orderCount": {Type: gql.NewNonNull(gql.Int), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.CustomerSummaryResponse).OrderCount, nil
+		}},
+		"totalSpent": {Type: gql.NewNonNull(gql.Float), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.CustomerSummaryResponse).TotalSpent, nil
+		}},
+		"lastOrderAt": {Type: gql.DateTime, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.CustomerSummaryResponse).LastOrderAt, nil
+		}},
+	},
+})
+
+var createOrderInput = gql.NewInputObject(gql.InputObjectConfig{
+	Name: "CreateOrderInput",
+	Fields: gql.InputObjectConfigFieldMap{
+		"customerId":      {Type: gql.NewNonNull(gql.ID)},
+		"customerName":    {Type: gql.String},
+		"shippingAddress": {Type: gql.String},
+		"items": {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.NewInputObject(gql.InputObjectConfig{
+			Name: "ItemInput",
+			Fields: gql.InputObjectConfigFieldMap{
+				"sku":      {Type: gql.NewNonNull(gql.String)},
+				"quantity": {Type: gql.NewNonNull(gql.Int)},
+			},
+		}))))},
+	},
+})
+
+// NewSchema builds the GraphQL schema over the order service. Order and customer lookups
+// are batched through the Loaders carried by the request context.
+func NewSchema(service orders.Service) (gql.Schema, error) {
+	r := &resolver{service: service}
+
+	orderType := gql.NewObject(gql.ObjectConfig{
+		Name: "Order",
+		Fields: gql.Fields{
+			"orderId":         {Type: gql.NewNonNull(gql.ID), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.OrderID })},
+			"customerId":      {Type: gql.NewNonNull(gql.ID), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.CustomerID })},
+			"customerName":    {Type: gql.String, Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.CustomerName })},
+			"shippingAddress": {Type: gql.String, Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.ShippingAddress })},
+			"status":          {Type: gql.NewNonNull(orderStatusEnum), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.Status })},
+			"items":           {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(itemType))), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.Items })},
+			"total":           {Type: gql.NewNonNull(gql.Float), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.Total })},
+			"createdAt":       {Type: gql.NewNonNull(gql.DateTime), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.CreatedAt })},
+			"updatedAt":       {Type: gql.NewNonNull(gql.DateTime), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.UpdatedAt })},
+			"archivedAt":      {Type: gql.DateTime, Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.ArchivedAt })},
+			"history":         {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(statusChangeType))), Resolve: orderField(func(o *models.OrderResponse) interface{} { return o.History })},
+			"customer":        {Type: customerSummaryType, Resolve: r.orderCustomer},
+		},
+	})
+
+	orderConnectionType := gql.NewObject(gql.ObjectConfig{
+		Name:        "OrderConnection",
+		Description: "A page of orders, newest first",
+		Fields: gql.Fields{
+			"nodes": {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(orderType))), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.ListOrdersResponse).Data, nil
+			}},
+			"total": {Type: gql.Int, Description: "Only counted when includeTotal is set", Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if total := p.Source.(*models.ListOrdersResponse).Total; total != nil {
+					return *total, nil
+				}
+				return nil, nil
+			}},
+			"hasMore": {Type: gql.NewNonNull(gql.Boolean), Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.ListOrdersResponse).HasMore, nil
+			}},
+			"nextCursor": {Type: gql.String, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return optionalString(p.Source.(*models.ListOrdersResponse).NextCursor), nil
+			}},
+			"prevCursor": {Type: gql.String, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return optionalString(p.Source.(*models.ListOrdersResponse).PrevCursor), nil
+			}},
+		},
+	})
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"order": {
+				Type:    orderType,
+				Args:    gql.FieldConfigArgument{"id": {Type: gql.NewNonNull(gql.ID)}},
+				Resolve: r.order,
+			},
+			"orders": {
+				Type: gql.NewNonNull(orderConnectionType),
+				Args: gql.FieldConfigArgument{
+					"status":       {Type: gql.NewList(gql.NewNonNull(orderStatusEnum))},
+					"customerId":   {Type: gql.ID},
+					"sku":          {Type: gql.String},
+					"createdFrom":  {Type: gql.DateTime},
+					"createdTo":    {Type: gql.DateTime},
+					"updatedFrom":  {Type: gql.DateTime},
+					"updatedTo":    {Type: gql.DateTime},
+					"minTotal":     {Type: gql.Float},
+					"maxTotal":     {Type: gql.Float},
+					"first":        {Type: gql.Int, DefaultValue: defaultFirst},
+					"after":        {Type: gql.String, Description: "Cursor of the page to read, nextCursor or prevCursor of a previous page"},
+					"includeTotal": {Type: gql.Boolean, DefaultValue: false},
+				},
+				Resolve: r.orders,
+			},
+			"customer": {
+				Type:    customerSummaryType,
+				Args:    gql.FieldConfigArgument{"id": {Type: gql.NewNonNull(gql.ID)}},
+				Resolve: r.customer,
+			},
+		},
+	})
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createOrder": {
+				Type:    gql.NewNonNull(orderType),
+				Args:    gql.FieldConfigArgument{"input": {Type: gql.NewNonNull(createOrderInput)}},
+				Resolve: r.createOrder,
+			},
+			"updateOrderStatus": {
+				Type: gql.NewNonNull(orderType),
+				Args: gql.FieldConfigArgument{
+					"id":     {Type: gql.NewNonNull(gql.ID)},
+					"status": {Type: gql.NewNonNull(orderStatusEnum)},
+				},
+				Resolve: r.updateOrderStatus,
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// resolver resolves the root fields of the schema
+type resolver struct {
+	service orders.Service
+}
+
+// order loads an order through the batching loader
+func (r *resolver) order(p gql.ResolveParams) (interface{}, error) {
+	load := r.loaders(p.Context).Orders.Load(p.Context, p.Args["id"].(string))
+	return func() (interface{}, error) {
+		order, err := load()
+		if err != nil {
+			return nil, toResolverError(err)
+		}
+		if order == nil {
+			return nil, toResolverError(errors.ErrOrderNotFound)
+		}
+		return order, nil
+	}, nil
+}
+
+// orders lists a page of orders with cursor pagination
+func (r *resolver) orders(p gql.ResolveParams) (interface{}, error) {
+	first := p.Args["first"].(int)
+	if first < 1 || first > maxFirst {
+		return nil, toResolverError(errors.ErrInvalidLimit)
+	}
+
+	includeTotal, _ := p.Args["includeTotal"].(bool)
+	opts := domain.ListOptions{
+		Limit:      first,
+		CursorMode: true,
+		SkipTotal:  !includeTotal,
+		Read:       orderRead,
+	}
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		cursor, err := domain.DecodeCursor(after)
+		if err != nil {
+			return nil, toResolverError(errors.ErrInvalidCursor)
+		}
+		opts.Cursor = cursor
+	}
+
+	filter := domain.OrderFilter{
+		CustomerID:  stringArg(p.Args, "customerId"),
+		SKU:         stringArg(p.Args, "sku"),
+		CreatedFrom: timeArg(p.Args, "createdFrom"),
+		CreatedTo:   timeArg(p.Args, "createdTo"),
+		UpdatedFrom: timeArg(p.Args, "updatedFrom"),
+		UpdatedTo:   timeArg(p.Args, "updatedTo"),
+		MinTotal:    floatArg(p.Args, "minTotal"),
+		MaxTotal:    floatArg(p.Args, "maxTotal"),
+	}
+	if statuses, ok := p.Args["status"].([]interface{}); ok {
+		for _, status := range statuses {
+			filter.Statuses = append(filter.Statuses, domain.OrderStatus(status.(string)))
+		}
+	}
+
+	page, err := r.service.ListOrders(p.Context, filter, opts)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+	return page, nil
+}
+
+// customer loads a customer summary through the batching loader
+func (r *resolver) customer(p gql.ResolveParams) (interface{}, error) {
+	return r.loadCustomer(p.Context, p.Args["id"].(string)), nil
+}
+
+// orderCustomer loads the summary of the customer of an order, batching the customers of
+// every order of a page into a single aggregation
+func (r *resolver) orderCustomer(p gql.ResolveParams) (interface{}, error) {
+	return r.loadCustomer(p.Context, p.Source.(*models.OrderResponse).CustomerID), nil
+}
+
+func (r *resolver) loadCustomer(ctx context.Context, customerID string) func() (interface{}, error) {
+	load := r.loaders(ctx).Customers.Load(ctx, customerID)
+	return func() (interface{}, error) {
+		summary, err := load()
+		if err != nil {
+			return nil, toResolverError(err)
+		}
+		if summary == nil {
+			return nil, nil
+		}
+		return summary, nil
+	}
+}
+
+// createOrder creates an order from the input object
+func (r *resolver) createOrder(p gql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+
+	req := &models.CreateOrderRequest{
+		CustomerID:      stringArg(input, "customerId"),
+		CustomerName:    stringArg(input, "customerName"),
+		ShippingAddress: stringArg(input, "shippingAddress"),
+	}
+	items, _ := input["items"].([]interface{})
+	for _, value := range items {
+		item := value.(map[string]interface{})
+		req.Items = append(req.Items, models.Items{
+			Sku:      item["sku"].(string),
+			Quantity: item["quantity"].(int),
+		})
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, toResolverError(err)
+	}
+
+	order, err := r.service.CreateOrder(p.Context, req.ToDomain())
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+	return order, nil
+}
+
+// updateOrderStatus moves an order to a new status and returns it as updated
+func (r *resolver) updateOrderStatus(p gql.ResolveParams) (interface{}, error) {
+	orderID := p.Args["id"].(string)
+	status := domain.OrderStatus(p.Args["status"].(string))
+
+	if err := r.service.UpdateOrderStatus(p.Context, orderID, status); err != nil {
+		return nil, toResolverError(err)
+	}
+
+	order, err := r.service.GetOrder(p.Context, orderID, orderRead)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+	return order, nil
+}
+
+// loaders returns the loaders of the request, or unshared ones when the context has none
+func (r *resolver) loaders(ctx context.Context) *Loaders {
+	if loaders := loadersFrom(ctx); loaders != nil {
+		return loaders
+	}
+	return NewLoaders(r.service)
+}
+
+// orderField resolves a field of an order
+func orderField(field func(order *models.OrderResponse) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return field(p.Source.(*models.OrderResponse)), nil
+	}
+}
+
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func timeArg(args map[string]interface{}, name string) *time.Time {
+	if value, ok := args[name].(time.Time); ok {
+		return &value
+	}
+	return nil
+}
+
+func floatArg(args map[string]interface{}, name string) *float64 {
+	if value, ok := args[name].(float64); ok {
+		return &value
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"order-management-ms/src/main/pkg/customerrors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain identifies the service in the ErrorInfo details of the errors
+const errorDomain = "order-management-ms"
+
+// toStatus converts an error to a gRPC status. API errors keep their message and carry their
+// code in an ErrorInfo detail; any other error is hidden behind an internal error.
+func toStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var apiErr customerrors.Error
+	if !errors.As(err, &apiErr) {
+		apiErr = customerrors.ErrInternalServer
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: apiErr.ErrorCode(), Domain: errorDomain}}
+
+	var validationErr *customerrors.ValidationErrorResponse
+	if errors.As(apiErr, &validationErr) && len(validationErr.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range validationErr.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st := status.New(grpcCode(apiErr), apiErr.Error())
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// grpcCode maps the HTTP status of an API error to the closest gRPC code
+func grpcCode(err customerrors.Error) codes.Code {
+	if errors.Is(err, customerrors.ErrInvalidTransition) {
+		return codes.FailedPrecondition
+	}
+
+	switch err.StatusCode() {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
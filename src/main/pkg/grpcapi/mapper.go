@@ -0,0 +1,117 @@
+package grpcapi
+
+import (
+	"strings"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	ordersv1 "order-management-ms/src/main/proto/orders/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// statusPrefix prefixes the names of the OrderStatus enum values
+const statusPrefix = "ORDER_STATUS_"
+
+func toProtoStatus(status string) ordersv1.OrderStatus {
+	return ordersv1.OrderStatus(ordersv1.OrderStatus_value[statusPrefix+status])
+}
+
+// fromProtoStatus returns the domain status of an enum value, empty when unspecified
+func fromProtoStatus(status ordersv1.OrderStatus) domain.OrderStatus {
+	if status == ordersv1.OrderStatus_ORDER_STATUS_UNSPECIFIED {
+		return ""
+	}
+	return domain.OrderStatus(strings.TrimPrefix(status.String(), statusPrefix))
+}
+
+func toProtoOrder(order *models.OrderResponse) *ordersv1.Order {
+	items := make([]*ordersv1.Item, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = &ordersv1.Item{Sku: item.Sku, Quantity: int32(item.Quantity), Price: item.Price}
+	}
+
+	history := make([]*ordersv1.StatusChange, len(order.History))
+	for i, change := range order.History {
+		history[i] = &ordersv1.StatusChange{
+			Status:    toProtoStatus(change.Status),
+			ChangedAt: timestamppb.New(change.ChangedAt),
+		}
+	}
+
+	return &ordersv1.Order{
+		OrderId:         order.OrderID,
+		CustomerId:      order.CustomerID,
+		CustomerName:    order.CustomerName,
+		ShippingAddress: order.ShippingAddress,
+		Status:          toProtoStatus(order.Status),
+		Items:           items,
+		Total:           order.Total,
+		CreatedAt:       timestamppb.New(order.CreatedAt),
+		UpdatedAt:       timestamppb.New(order.UpdatedAt),
+		ArchivedAt:      optionalTimestamp(order.ArchivedAt),
+		History:         history,
+	}
+}
+
+func toCreateOrderRequest(req *ordersv1.CreateOrderRequest) *models.CreateOrderRequest {
+	items := make([]models.Items, len(req.GetItems()))
+	for i, item := range req.GetItems() {
+		items[i] = models.Items{Sku: item.GetSku(), Quantity: int(item.GetQuantity())}
+	}
+
+	return &models.CreateOrderRequest{
+		CustomerID:      req.GetCustomerId(),
+		CustomerName:    req.GetCustomerName(),
+		ShippingAddress: req.GetShippingAddress(),
+		Items:           items,
+	}
+}
+
+func toOrderFilter(req *ordersv1.ListOrdersRequest) domain.OrderFilter {
+	filter := domain.OrderFilter{
+		CustomerID:  req.GetCustomerId(),
+		SKU:         req.GetSku(),
+		CreatedFrom: optionalTime(req.GetCreatedFrom()),
+		CreatedTo:   optionalTime(req.GetCreatedTo()),
+		UpdatedFrom: optionalTime(req.GetUpdatedFrom()),
+		UpdatedTo:   optionalTime(req.GetUpdatedTo()),
+		MinTotal:    req.MinTotal,
+		MaxTotal:    req.MaxTotal,
+	}
+	for _, status := range req.GetStatuses() {
+		filter.Statuses = append(filter.Statuses, fromProtoStatus(status))
+	}
+	return filter
+}
+
+func toListOrdersResponse(page *models.ListOrdersResponse) *ordersv1.ListOrdersResponse {
+	orders := make([]*ordersv1.Order, len(page.Data))
+	for i, order := range page.Data {
+		orders[i] = toProtoOrder(order)
+	}
+
+	return &ordersv1.ListOrdersResponse{
+		Orders:        orders,
+		HasMore:       page.HasMore,
+		NextPageToken: page.NextCursor,
+		PrevPageToken: page.PrevCursor,
+		Total:         page.Total,
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,177 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	models "order-management-ms/src/main/models/api"
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/validation"
+	ordersv1 "order-management-ms/src/main/proto/orders/v1"
+	"order-management-ms/src/main/services/orders"
+
+	"go.uber.org/zap"
+)
+
+// Default and maximum page sizes of ListOrders, the same as the REST listing
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// OrderServer implements the gRPC OrderService over the order service
+type OrderServer struct {
+	ordersv1.UnimplementedOrderServiceServer
+
+	service       orders.Service
+	watchInterval time.Duration
+	logger        *zap.Logger
+}
+
+func NewOrderServer(service orders.Service, watchInterval time.Duration, logger *zap.Logger) *OrderServer {
+	return &OrderServer{
+		service:       service,
+		watchInterval: watchInterval,
+		logger:        logger,
+	}
+}
+
+// CreateOrder registers a new order
+func (s *OrderServer) CreateOrder(ctx context.Context, req *ordersv1.CreateOrderRequest) (*ordersv1.Order, error) {
+	createReq := toCreateOrderRequest(req)
+	if err := validation.Struct(createReq); err != nil {
+		return nil, toStatus(err)
+	}
+
+	order, err := s.service.CreateOrder(ctx, createReq.ToDomain())
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to create order", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return toProtoOrder(order), nil
+}
+
+// GetOrder retrieves an order by ID
+func (s *OrderServer) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.Order, error) {
+	if req.GetOrderId() == "" {
+		return nil, toStatus(errors.ErrInvalidOrderID)
+	}
+
+	var read domain.ReadOptions
+	if req.GetIncludeHistory() {
+		read.Expand = []string{domain.ExpandHistory}
+	}
+
+	order, err := s.service.GetOrder(ctx, req.GetOrderId(), read)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoOrder(order), nil
+}
+
+// ListOrders lists a page of orders with cursor pagination
+func (s *OrderServer) ListOrders(ctx context.Context, req *ordersv1.ListOrdersRequest) (*ordersv1.ListOrdersResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, toStatus(errors.ErrInvalidLimit)
+	}
+
+	opts := domain.ListOptions{
+		Limit:      pageSize,
+		CursorMode: true,
+		SkipTotal:  !req.GetIncludeTotal(),
+	}
+	if req.GetPageToken() != "" {
+		cursor, err := domain.DecodeCursor(req.GetPageToken())
+		if err != nil {
+			return nil, toStatus(errors.ErrInvalidCursor)
+		}
+		opts.Cursor = cursor
+	}
+
+	filter := toOrderFilter(req)
+	for _, status := range filter.Statuses {
+		if !domain.IsValidStatus(status) {
+			return nil, toStatus(errors.ErrInvalidStatus)
+		}
+	}
+
+	page, err := s.service.ListOrders(ctx, filter, opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toListOrdersResponse(page), nil
+}
+
+// UpdateOrderStatus moves an order to a new status and returns it as updated
+func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *ordersv1.UpdateOrderStatusRequest) (*ordersv1.Order, error) {
+	if req.GetOrderId() == "" {
+		return nil, toStatus(errors.ErrInvalidOrderID)
+	}
+
+	newStatus := fromProtoStatus(req.GetStatus())
+	if !domain.IsValidStatus(newStatus) {
+		return nil, toStatus(errors.ErrInvalidStatus)
+	}
+
+	if err := s.service.UpdateOrderStatus(ctx, req.GetOrderId(), newStatus); err != nil {
+		return nil, toStatus(err)
+	}
+
+	order, err := s.service.GetOrder(ctx, req.GetOrderId(), domain.ReadOptions{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoOrder(order), nil
+}
+
+// WatchOrder sends the current state of an order and then checks it for status changes
+// every watch interval, until the order reaches a final status or the client cancels
+func (s *OrderServer) WatchOrder(req *ordersv1.WatchOrderRequest, stream ordersv1.OrderService_WatchOrderServer) error {
+	if req.GetOrderId() == "" {
+		return toStatus(errors.ErrInvalidOrderID)
+	}
+
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	var last *models.OrderResponse
+	for {
+		order, err := s.service.GetOrder(ctx, req.GetOrderId(), domain.ReadOptions{})
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if last == nil || order.Status != last.Status {
+			if err := stream.Send(toProtoOrder(order)); err != nil {
+				return err
+			}
+			last = order
+		}
+
+		if isFinalStatus(domain.OrderStatus(order.Status)) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return toStatus(ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// isFinalStatus checks if an order can no longer change status
+func isFinalStatus(status domain.OrderStatus) bool {
+	return status == domain.StatusDelivered || status == domain.StatusCancelled
+}
@@ -0,0 +1,256 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/auth"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
+	ordersv1 "order-management-ms/src/main/proto/orders/v1"
+	"order-management-ms/src/main/services/orders"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer creates the gRPC server with the order, health and, when enabled, reflection
+// services. Order calls are authenticated with the API key of the API key header metadata,
+// unless keys is nil, or else with the bearer token of the authorization metadata, unless
+// verifier is nil. They are authorized with the policy or the scopes of the key and scoped to
+// the tenant of the caller, or the one sent in the tenant header metadata when authentication
+// is disabled. Every call gets the request ID sent in the x-request-id
+// metadata, or a generated one, echoed in the response header metadata.
+func NewServer(cfg *config.Config, service orders.Service, verifier *auth.Verifier, keys apikey.Authenticator, policy authz.Policy, logger *zap.Logger) *grpc.Server {
+	header := strings.ToLower(cfg.Tenant.Header)
+	unary := []grpc.UnaryServerInterceptor{requestIDInterceptor(), loggingInterceptor(logger)}
+	stream := []grpc.StreamServerInterceptor{requestIDStreamInterceptor()}
+	if verifier != nil || keys != nil {
+		creds := &credentials{verifier: verifier, keys: keys, keyHeader: strings.ToLower(cfg.APIKeys.Header), policy: policy}
+		unary = append(unary, authInterceptor(creds))
+		stream = append(stream, authStreamInterceptor(creds))
+	}
+	unary = append(unary, tenantInterceptor(header, cfg.Tenant.Default))
+	stream = append(stream, tenantStreamInterceptor(header, cfg.Tenant.Default))
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+
+	ordersv1.RegisterOrderServiceServer(srv, NewOrderServer(service, cfg.GRPC.WatchInterval, logger))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(ordersv1.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	if cfg.GRPC.Reflection {
+		reflection.Register(srv)
+	}
+
+	return srv
+}
+
+// requestIDMetadata is the metadata key carrying the request ID in calls and responses
+var requestIDMetadata = strings.ToLower(requestid.Header)
+
+// resolveRequestID stores the request ID sent in the metadata, or a generated one, in the
+// context and returns it
+func resolveRequestID(ctx context.Context) (context.Context, string) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	requestID = requestid.Resolve(requestID)
+	return requestid.NewContext(ctx, requestID), requestID
+}
+
+func requestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, requestID := resolveRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+		return handler(ctx, req)
+	}
+}
+
+func requestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestID := resolveRequestID(stream.Context())
+		_ = stream.SetHeader(metadata.Pairs(requestIDMetadata, requestID))
+		return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// isOrderService checks if a full method name belongs to the order service, the only one
+// scoped to a tenant
+func isOrderService(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+ordersv1.OrderService_ServiceDesc.ServiceName+"/")
+}
+
+// resolveTenant binds the call to its tenant like the HTTP tenant middleware: the tenant of
+// the caller's token, or the one sent in the metadata when the caller is not bound to one
+func resolveTenant(ctx context.Context, header, defaultTenant string) (context.Context, error) {
+	var tenantID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(header); len(values) > 0 {
+			tenantID = values[0]
+		}
+	}
+
+	ctx, err := tenant.Bind(ctx, tenantID, defaultTenant)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return ctx, nil
+}
+
+// credentials checks the API keys with keys and the bearer tokens with verifier, each unless
+// nil
+type credentials struct {
+	verifier  *auth.Verifier
+	keys      apikey.Authenticator
+	keyHeader string
+	policy    authz.Policy
+}
+
+// authenticate binds the context to the API key sent in the metadata like the HTTP API key
+// middleware, or else stores the claims of the bearer token and the access the policy grants
+// to their roles. Calls without an API key are rejected when bearer tokens are not checked.
+func (c *credentials) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if c.keys != nil {
+		if values := md.Get(c.keyHeader); len(values) > 0 && values[0] != "" {
+			ctx, err := apikey.Authenticate(ctx, c.keys, values[0], clientIP(ctx))
+			if err != nil {
+				return nil, toStatus(err)
+			}
+			return ctx, nil
+		}
+		if c.verifier == nil {
+			return nil, toStatus(customerrors.ErrMissingAPIKey)
+		}
+	}
+
+	var authorization string
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	claims, err := c.verifier.Authenticate(ctx, authorization)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return auth.NewContext(ctx, claims, c.policy), nil
+}
+
+// clientIP returns the address of the caller, without its port
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func authInterceptor(creds *credentials) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isOrderService(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := creds.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authStreamInterceptor(creds *credentials) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !isOrderService(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := creds.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func tenantInterceptor(header, defaultTenant string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isOrderService(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := resolveTenant(ctx, header, defaultTenant)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tenantStreamInterceptor(header, defaultTenant string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !isOrderService(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := resolveTenant(stream.Context(), header, defaultTenant)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// tenantStream overrides the context of a stream with the one carrying the request ID, the
+// tenant or the caller
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// loggingInterceptor logs the unary calls like the HTTP logging middleware
+func loggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		requestid.Logger(ctx, logger).Info("gRPC call",
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("latency", time.Since(start)),
+		)
+		return resp, err
+	}
+}
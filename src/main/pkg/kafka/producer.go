@@ -5,11 +5,20 @@ import (
 	"encoding/json"
 
 	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
 
+const (
+	// TenantHeader is the message header carrying the tenant of the order
+	TenantHeader = "tenant_id"
+	// RequestIDHeader is the message header carrying the ID of the request that produced the event
+	RequestIDHeader = "request_id"
+)
+
 type Writer interface {
 	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
 	Close() error
@@ -56,7 +65,7 @@ func (p *Producer) PublishOrderStatusChanged(ctx context.Context, event kafkaDto
 	// Convert event to JSON
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
-		p.logger.Error("Error serializing event",
+		requestid.Logger(ctx, p.logger).Error("Error serializing event",
 			zap.Error(err),
 			zap.String("order_id", event.OrderID),
 		)
@@ -65,13 +74,14 @@ func (p *Producer) PublishOrderStatusChanged(ctx context.Context, event kafkaDto
 
 	// Publish message to Kafka
 	msg := kafka.Message{
-		Key:   []byte(event.OrderID),
-		Value: eventJSON,
+		Key:     []byte(event.OrderID),
+		Value:   eventJSON,
+		Headers: messageHeaders(ctx),
 	}
 
 	err = p.writer.WriteMessages(ctx, msg)
 	if err != nil {
-		p.logger.Error("Failed to publish message to Kafka",
+		requestid.Logger(ctx, p.logger).Error("Failed to publish message to Kafka",
 			zap.Error(err),
 			zap.String("order_id", event.OrderID),
 			zap.String("topic", p.topic),
@@ -79,7 +89,7 @@ func (p *Producer) PublishOrderStatusChanged(ctx context.Context, event kafkaDto
 		return err
 	}
 
-	p.logger.Debug("Successfully published order status change event",
+	requestid.Logger(ctx, p.logger).Debug("Successfully published order status change event",
 		zap.String("order_id", event.OrderID),
 		zap.String("old_status", string(event.OldStatus)),
 		zap.String("new_status", string(event.NewStatus)),
@@ -91,3 +101,15 @@ func (p *Producer) PublishOrderStatusChanged(ctx context.Context, event kafkaDto
 func (p *Producer) Close() error {
 	return p.writer.Close()
 }
+
+// messageHeaders builds the Kafka headers carrying the request metadata stored in ctx
+func messageHeaders(ctx context.Context) []kafka.Header {
+	var headers []kafka.Header
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		headers = append(headers, kafka.Header{Key: TenantHeader, Value: []byte(tenantID)})
+	}
+	if requestID, ok := requestid.FromContext(ctx); ok {
+		headers = append(headers, kafka.Header{Key: RequestIDHeader, Value: []byte(requestID)})
+	}
+	return headers
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/requestid"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
@@ -32,7 +33,7 @@ func (k *Publisher) PublishOrderStatusChanged(ctx context.Context, event kafkaDt
 	// Serialize the event to JSON
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
-		k.logger.Error("Failed to serialize event",
+		requestid.Logger(ctx, k.logger).Error("Failed to serialize event",
 			zap.Error(err),
 			zap.String("order_id", event.OrderID),
 		)
@@ -41,12 +42,13 @@ func (k *Publisher) PublishOrderStatusChanged(ctx context.Context, event kafkaDt
 
 	// Publish the message to Kafka
 	err = k.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(event.OrderID),
-		Value: eventJSON,
+		Key:     []byte(event.OrderID),
+		Value:   eventJSON,
+		Headers: messageHeaders(ctx),
 	})
 
 	if err != nil {
-		k.logger.Error("Failed to publish message to Kafka",
+		requestid.Logger(ctx, k.logger).Error("Failed to publish message to Kafka",
 			zap.Error(err),
 			zap.String("topic", k.topic),
 			zap.String("order_id", event.OrderID),
@@ -54,7 +56,7 @@ func (k *Publisher) PublishOrderStatusChanged(ctx context.Context, event kafkaDt
 		return err
 	}
 
-	k.logger.Debug("Event published successfully",
+	requestid.Logger(ctx, k.logger).Debug("Event published successfully",
 		zap.String("topic", k.topic),
 		zap.String("order_id", event.OrderID),
 		zap.String("old_status", string(event.OldStatus)),
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow counts the requests of the current fixed window and weighs the count of the
+// previous one by the part of it still covered by the sliding window, which approximates a
+// true sliding window with two counters. A rejected request is not counted.
+//
+// KEYS[1] is the counter of the current window, KEYS[2] the counter of the previous one.
+// ARGV[1] is the limit, ARGV[2] the window in milliseconds and ARGV[3] the milliseconds
+// elapsed in the current window. It returns {allowed, count}.
+var slidingWindow = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local count = math.floor(previous * (window - elapsed) / window) + current
+
+if count >= limit then
+  return {0, count}
+end
+
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, count + 1}
+`)
+
+// windowCount returns the weighted count of the sliding window without counting a request.
+// KEYS and ARGV are the ones of slidingWindow, without the limit.
+var windowCount = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local elapsed = tonumber(ARGV[2])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+return math.floor(previous * (window - elapsed) / window) + current
+`)
+
+// Result is the outcome of counting a request against a limit
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the current window ends
+	Reset time.Duration
+}
+
+// Limiter counts requests in a sliding window shared by every instance through Redis
+type Limiter struct {
+	client redis.Scripter
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter storing its counters in Redis
+func NewLimiter(client redis.Scripter) *Limiter {
+	return &Limiter{client: client, now: time.Now}
+}
+
+// Allow counts a request of the key against limit requests per window
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	keys, windowMs, elapsed := l.window(key, window)
+	values, err := slidingWindow.Run(ctx, l.client, keys, limit, windowMs, elapsed).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(values[0] == 1, limit, int(values[1]), windowMs, elapsed), nil
+}
+
+// Peek checks if a request of the key would be allowed, without counting it
+func (l *Limiter) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	keys, windowMs, elapsed := l.window(key, window)
+	count, err := windowCount.Run(ctx, l.client, keys, windowMs, elapsed).Int()
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(count < limit, limit, count, windowMs, elapsed), nil
+}
+
+// window returns the counters of the current and the previous fixed windows of the key, the
+// window in milliseconds and the milliseconds elapsed in the current window
+func (l *Limiter) window(key string, window time.Duration) ([]string, int64, int64) {
+	windowMs := window.Milliseconds()
+	nowMs := l.now().UnixMilli()
+	start := nowMs - nowMs%windowMs
+
+	keys := []string{
+		"ratelimit:" + key + ":" + strconv.FormatInt(start, 10),
+		"ratelimit:" + key + ":" + strconv.FormatInt(start-windowMs, 10),
+	}
+	return keys, windowMs, nowMs - start
+}
+
+func newResult(allowed bool, limit, count int, windowMs, elapsed int64) Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Duration(windowMs-elapsed) * time.Millisecond,
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Middleware limits the requests of each client to the route group, answering 429 once the
+// limit is reached. Every response carries the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers. It must run after the authentication, which identifies the API
+// key clients. Groups without a limit are not limited, and requests are let through when
+// Redis does not answer in time.
+func Middleware(limiter *Limiter, cfg config.RateLimit, group string, logger *zap.Logger) gin.HandlerFunc {
+	groupLimit := cfg.Limits[group]
+	if limiter == nil || groupLimit <= 0 || cfg.Window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	window := ";w=" + strconv.Itoa(int(cfg.Window.Seconds()))
+
+	return func(c *gin.Context) {
+		client, clientKey := clientOf(c)
+		limit := groupLimit
+		if override, ok := cfg.Overrides[client+"/"+group]; ok {
+			limit = override
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cfg.Timeout)
+		result, err := limiter.Allow(ctx, group+":"+clientKey, limit, cfg.Window)
+		cancel()
+		if err != nil {
+			logger.Warn("Rate limiter unavailable, letting the request through", zap.Error(err), zap.String("group", group))
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+		c.Header("RateLimit-Policy", strconv.Itoa(limit)+window)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", reset)
+
+		if !result.Allowed {
+			logger.Info("Rate limited request", zap.String("group", group), zap.String("client", clientKey))
+			c.Header("Retry-After", reset)
+			_ = c.Error(errors.ErrRateLimited)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// FailedAuthentication limits the failed authentications of each client IP to the limit of
+// the route group. Once it is reached, requests from the IP are rejected with 429 before their
+// credentials are checked, so invalid tokens and API keys cannot flood the key set and the API
+// key store. Without a limit for the group, authenticate is returned as is.
+func FailedAuthentication(limiter *Limiter, cfg config.RateLimit, group string, authenticate gin.HandlerFunc, logger *zap.Logger) gin.HandlerFunc {
+	limit := cfg.Limits[group]
+	if limiter == nil || limit <= 0 || cfg.Window <= 0 {
+		return authenticate
+	}
+
+	return func(c *gin.Context) {
+		clientKey := group + ":ip:" + c.ClientIP()
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cfg.Timeout)
+		result, err := limiter.Peek(ctx, clientKey, limit, cfg.Window)
+		cancel()
+		if err != nil {
+			logger.Warn("Rate limiter unavailable, letting the request through", zap.Error(err), zap.String("group", group))
+		} else if !result.Allowed {
+			logger.Info("Rate limited request", zap.String("group", group), zap.String("client", clientKey))
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+			_ = c.Error(errors.ErrRateLimited)
+			c.Abort()
+			return
+		}
+
+		// Authenticated requests carry the access of the caller, even when a later handler
+		// aborts them
+		authenticate(c)
+		if _, ok := authz.FromContext(c.Request.Context()); ok || !c.IsAborted() {
+			return
+		}
+
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(c.Request.Context()), cfg.Timeout)
+		defer cancel()
+		if _, err := limiter.Allow(ctx, clientKey, limit, cfg.Window); err != nil {
+			logger.Warn("Failed to count a failed authentication", zap.Error(err), zap.String("group", group))
+		}
+	}
+}
+
+// clientOf identifies the client of a request by its API key, or else its IP address. It
+// returns the identifier used in the overrides and the one used in the counter keys.
+func clientOf(c *gin.Context) (string, string) {
+	if keyID, ok := apikey.FromContext(c.Request.Context()); ok {
+		return keyID, "key:" + keyID
+	}
+	ip := c.ClientIP()
+	return ip, "ip:" + ip
+}
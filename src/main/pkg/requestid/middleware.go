@@ -0,0 +1,17 @@
+package requestid
+
+import "github.com/gin-gonic/gin"
+
+// Header is the HTTP header carrying the request ID in requests and responses
+const Header = "X-Request-ID"
+
+// Middleware binds the request ID sent in the X-Request-ID header, or a generated one, to
+// the request context and echoes it in the response
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := Resolve(c.GetHeader(Header))
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), requestID))
+		c.Header(Header, requestID)
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// validID restricts the request IDs sent by clients to characters that are safe in headers
+// and logs
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns the request ID stored in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(contextKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// Resolve returns the request ID sent by the client, or a new one when none or an invalid
+// one was sent
+func Resolve(requestID string) string {
+	if validID.MatchString(requestID) {
+		return requestID
+	}
+	return uuid.New().String()
+}
+
+// Logger returns logger with the request ID stored in ctx, so the logs of a request can be
+// correlated. It returns logger itself when ctx carries no request ID.
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if requestID, ok := FromContext(ctx); ok {
+		return logger.With(zap.String("request_id", requestID))
+	}
+	return logger
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	domain "order-management-ms/src/main/models/datastore"
+)
+
+// Searchable fields of an order, as named in the highlights
+const (
+	FieldOrderID         = "order_id"
+	FieldSKU             = "items.sku"
+	FieldCustomerName    = "customer_name"
+	FieldShippingAddress = "shipping_address"
+)
+
+// Terms splits a query into its distinct lower-cased terms
+func Terms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Highlight HTML-escapes the text and wraps every case-insensitive occurrence of the terms
+// in <em> tags. It reports whether any term matched.
+func Highlight(text string, terms []string) (string, bool) {
+	if text == "" || len(terms) == 0 {
+		return "", false
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	matches := pattern.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+		b.WriteString("</em>")
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+
+	return b.String(), true
+}
+
+// HighlightOrder returns the searchable fields of the order that match the terms, highlighted
+func HighlightOrder(order *domain.Order, terms []string) map[string][]string {
+	highlights := make(map[string][]string)
+
+	add := func(field, text string) {
+		if highlighted, ok := Highlight(text, terms); ok {
+			highlights[field] = append(highlights[field], highlighted)
+		}
+	}
+
+	add(FieldOrderID, order.OrderID)
+	for _, item := range order.Items {
+		add(FieldSKU, item.Sku)
+	}
+	add(FieldCustomerName, order.CustomerName)
+	add(FieldShippingAddress, order.ShippingAddress)
+
+	return highlights
+}
@@ -0,0 +1,23 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware binds the request to its tenant and stores it in the request context. Callers
+// bound to a tenant by their credentials, like tokens and API keys, keep it and may not ask for
+// another one in the header without the AllTenants permission. The other callers get the
+// tenant of the header, or defaultTenant when it is configured.
+func Middleware(header, defaultTenant string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := Bind(c.Request.Context(), c.GetHeader(header), defaultTenant)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
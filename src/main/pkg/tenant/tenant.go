@@ -0,0 +1,70 @@
+package tenant
+
+import (
+	"context"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"regexp"
+)
+
+type contextKey struct{}
+
+// validID restricts tenant IDs to characters that are safe in cache keys and headers
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// NewContext returns a copy of ctx carrying the tenant ID
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext returns the tenant ID stored in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(contextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// IsValidID checks if the tenant ID has a valid format
+func IsValidID(tenantID string) bool {
+	return validID.MatchString(tenantID)
+}
+
+// Resolve returns the tenant sent by the client, or defaultTenant when none was sent. It
+// fails when neither is set or the tenant ID has an invalid format.
+func Resolve(tenantID, defaultTenant string) (string, errors.Error) {
+	if tenantID == "" {
+		tenantID = defaultTenant
+	}
+
+	if tenantID == "" {
+		return "", errors.ErrMissingTenant
+	}
+	if !IsValidID(tenantID) {
+		return "", errors.ErrInvalidTenant
+	}
+	return tenantID, nil
+}
+
+// Bind returns a copy of ctx carrying the tenant of the request. Callers whose credentials
+// bound them to a tenant keep it, and may only ask for another one with the AllTenants
+// permission. The other callers get the requested tenant, resolved like Resolve.
+func Bind(ctx context.Context, requested, defaultTenant string) (context.Context, errors.Error) {
+	bound, ok := FromContext(ctx)
+	if !ok {
+		tenantID, err := Resolve(requested, defaultTenant)
+		if err != nil {
+			return nil, err
+		}
+		return NewContext(ctx, tenantID), nil
+	}
+
+	if requested == "" || requested == bound {
+		return ctx, nil
+	}
+	if access, ok := authz.FromContext(ctx); !ok || !access.Can(authz.AllTenants) {
+		return nil, errors.ErrForbidden
+	}
+	if !IsValidID(requested) {
+		return nil, errors.ErrInvalidTenant
+	}
+	return NewContext(ctx, requested), nil
+}
@@ -0,0 +1,144 @@
+// Package validation registers the custom binding rules of the request models and translates
+// the errors of failed validations into field-level validation errors.
+package validation
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	errors "order-management-ms/src/main/pkg/customerrors"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// validSKU matches stock keeping units such as JNS-CLS-32
+	validSKU = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	// validCustomerID matches customer IDs, which end up in cache keys and query filters
+	validCustomerID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+)
+
+// The custom rules are registered on the gin validator, so they can be used in the binding
+// tags of the request models
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Name the fields after their JSON or query key in the errors
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	_ = v.RegisterValidation("sku", matches(validSKU))
+	_ = v.RegisterValidation("customer_id", matches(validCustomerID))
+}
+
+func matches(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(fl.Field().String())
+	}
+}
+
+// Struct validates a request model against its binding tags, for the transports that do not
+// bind through gin
+func Struct(req interface{}) *errors.ValidationErrorResponse {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return NewError(err)
+	}
+	return nil
+}
+
+// NewError translates an error binding a request into a validation error listing each
+// failing field and its reason
+func NewError(err error) *errors.ValidationErrorResponse {
+	var validationErrs validator.ValidationErrors
+	if stderrors.As(err, &validationErrs) {
+		fields := make([]errors.ValidationError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fields[i] = errors.ValidationError{Field: fieldPath(fieldErr), Message: fieldReason(fieldErr)}
+		}
+		return errors.NewValidationError("request validation failed", fields)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if stderrors.As(err, &typeErr) {
+		return errors.NewValidationError("request validation failed", []errors.ValidationError{
+			{Field: jsonFieldPath(typeErr.Field), Message: "must be of type " + typeErr.Type.String()},
+		})
+	}
+
+	var syntaxErr *json.SyntaxError
+	if stderrors.Is(err, io.EOF) || stderrors.As(err, &syntaxErr) {
+		return errors.NewValidationError("request body must be valid JSON", nil)
+	}
+
+	return errors.NewValidationError(err.Error(), nil)
+}
+
+// fieldPath returns the path of the failing field from the request root, e.g. items[0].sku
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// jsonFieldPath formats the dotted path of a JSON decoding error like the other fields, e.g.
+// items.0.quantity as items[0].quantity
+func jsonFieldPath(field string) string {
+	var path strings.Builder
+	for _, segment := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			path.WriteString("[" + segment + "]")
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(segment)
+	}
+	return path.String()
+}
+
+// fieldReason describes the rule a field failed
+func fieldReason(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s elements", fieldErr.Param())
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "gte":
+		return "must be greater than or equal to " + fieldErr.Param()
+	case "sku":
+		return "must be a valid SKU: letters, digits, '.', '_' and '-', up to 64 characters"
+	case "customer_id":
+		return "must be a valid customer ID: letters, digits, '_' and '-', up to 64 characters"
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
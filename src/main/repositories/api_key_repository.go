@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+)
+
+// APIKeyRepository defines the interface for API key data access. The management operations
+// are scoped to the tenant in the context; authentication looks keys up across tenants.
+type APIKeyRepository interface {
+	// CreateKey saves a new API key
+	CreateKey(ctx context.Context, key *domain.APIKey) error
+
+	// ListKeys returns the API keys of the tenant, newest first
+	ListKeys(ctx context.Context) ([]*domain.APIKey, error)
+
+	// FindKey finds an API key by its key ID, whatever its tenant
+	FindKey(ctx context.Context, keyID string) (*domain.APIKey, error)
+
+	// RotateKey replaces the hash of an active API key, keeping the current one valid until
+	// previousExpiresAt
+	RotateKey(ctx context.Context, keyID, hash string, previousExpiresAt time.Time) (*domain.APIKey, error)
+
+	// RevokeKey revokes an API key
+	RevokeKey(ctx context.Context, keyID string) error
+
+	// TouchKey records the last use of an API key
+	TouchKey(ctx context.Context, keyID string, usedAt time.Time) error
+}
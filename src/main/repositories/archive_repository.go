@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+)
+
+// ArchiveRepository defines the interface for moving orders to cold storage
+type ArchiveRepository interface {
+	// ArchiveBefore moves up to batchSize orders in the given statuses, last updated before
+	// the cutoff, to the archive. It returns the number of orders archived.
+	ArchiveBefore(ctx context.Context, statuses []domain.OrderStatus, cutoff time.Time, batchSize int) (int, error)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/search"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// fieldWeights rank matches the same way as the MongoDB text index
+var fieldWeights = map[string]float64{
+	search.FieldOrderID:         10,
+	search.FieldSKU:             5,
+	search.FieldCustomerName:    3,
+	search.FieldShippingAddress: 1,
+}
+
+// OrderSearchIndex is an in-memory implementation of SearchIndex for tests and local
+// development. Unlike the MongoDB index it matches partial words in every field.
+type OrderSearchIndex struct {
+	mu     sync.RWMutex
+	orders map[string]map[string]*domain.Order
+}
+
+// NewOrderSearchIndex creates an empty in-memory search index
+func NewOrderSearchIndex() *OrderSearchIndex {
+	return &OrderSearchIndex{
+		orders: make(map[string]map[string]*domain.Order),
+	}
+}
+
+// Index adds or replaces an order in the index
+func (i *OrderSearchIndex) Index(order *domain.Order) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.orders[order.TenantID] == nil {
+		i.orders[order.TenantID] = make(map[string]*domain.Order)
+	}
+	i.orders[order.TenantID][order.OrderID] = order
+}
+
+// Search scores every order of the tenant by the weighted number of terms each field contains
+func (i *OrderSearchIndex) Search(ctx context.Context, query string, limit int) ([]*domain.SearchHit, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errors.ErrMissingTenant
+	}
+
+	terms := search.Terms(query)
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var hits []*domain.SearchHit
+	for _, order := range i.orders[tenantID] {
+		highlights := search.HighlightOrder(order, terms)
+		if len(highlights) == 0 {
+			continue
+		}
+
+		score := 0.0
+		for field, values := range highlights {
+			for _, value := range values {
+				score += fieldWeights[field] * float64(strings.Count(value, "<em>"))
+			}
+		}
+		hits = append(hits, &domain.SearchHit{Order: order, Score: score, Highlights: highlights})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Order.OrderID < hits[b].Order.OrderID
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// apiKeyIndexes are the indexes of the API keys collection. Authentication looks keys up by
+// their globally unique key ID, the management listings by tenant.
+var apiKeyIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "key_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}},
+	},
+}
+
+// APIKeyRepositoryMongoDB implements APIKeyRepository for MongoDB
+type APIKeyRepositoryMongoDB struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewAPIKeyRepository creates a new MongoDB API key repository
+func NewAPIKeyRepository(db *mongo.Database, collectionName string, logger *zap.Logger) *APIKeyRepositoryMongoDB {
+	return &APIKeyRepositoryMongoDB{
+		collection: db.Collection(collectionName),
+		logger:     logger,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on
+func (r *APIKeyRepositoryMongoDB) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.collection.Indexes().CreateMany(ctx, apiKeyIndexes); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to create API key indexes", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CreateKey saves a new API key for the tenant in the context
+func (r *APIKeyRepositoryMongoDB) CreateKey(ctx context.Context, key *domain.APIKey) error {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errors.ErrMissingTenant
+	}
+	key.TenantID = tenantID
+
+	if _, err := r.collection.InsertOne(ctx, key); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to create API key", zap.Error(err), zap.String("key_id", key.KeyID))
+		return err
+	}
+	return nil
+}
+
+// ListKeys returns the API keys of the tenant in the context, newest first
+func (r *APIKeyRepositoryMongoDB) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	filter, err := tenantFilter(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to list API keys", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to decode API keys", zap.Error(err))
+		return nil, err
+	}
+	return keys, nil
+}
+
+// FindKey finds an API key by its key ID across tenants, for authentication
+func (r *APIKeyRepositoryMongoDB) FindKey(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"key_id": keyID}).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		requestid.Logger(ctx, r.logger).Error("Failed to find API key", zap.Error(err), zap.String("key_id", keyID))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RotateKey replaces the hash of an active API key of the tenant in the context. The current
+// hash becomes the previous one, valid until previousExpiresAt.
+func (r *APIKeyRepositoryMongoDB) RotateKey(ctx context.Context, keyID, hash string, previousExpiresAt time.Time) (*domain.APIKey, error) {
+	filter, err := tenantFilter(ctx, bson.M{"key_id": keyID, "revoked_at": nil})
+	if err != nil {
+		return nil, err
+	}
+
+	// An update pipeline, so the previous hash is copied from the stored document
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"previous_hash":       "$hash",
+		"previous_expires_at": previousExpiresAt,
+		"hash":                hash,
+		"rotated_at":          time.Now(),
+	}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var key domain.APIKey
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&key); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		requestid.Logger(ctx, r.logger).Error("Failed to rotate API key", zap.Error(err), zap.String("key_id", keyID))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeKey revokes an API key of the tenant in the context. Revoking a revoked key keeps
+// its first revocation time.
+func (r *APIKeyRepositoryMongoDB) RevokeKey(ctx context.Context, keyID string) error {
+	filter, err := tenantFilter(ctx, bson.M{"key_id": keyID})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", time.Now()}},
+	}}}})
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to revoke API key", zap.Error(err), zap.String("key_id", keyID))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchKey records the last use of an API key
+func (r *APIKeyRepositoryMongoDB) TouchKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key_id": keyID}, bson.M{"$max": bson.M{"last_used_at": usedAt}})
+	return err
+}
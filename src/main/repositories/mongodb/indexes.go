@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// orderIndexes are the indexes of the orders collection. Listings always filter by tenant,
+// so every index starts with tenant_id, followed by the filter or sort keys it serves.
+var orderIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "order_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "customer_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "items.sku", Value: 1}, {Key: "created_at", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "total", Value: -1}, {Key: "_id", Value: -1}},
+	},
+	{
+		// Full-text search, weighted so order ID and SKU matches rank above customer details
+		Keys: bson.D{
+			{Key: "tenant_id", Value: 1},
+			{Key: "order_id", Value: "text"},
+			{Key: "items.sku", Value: "text"},
+			{Key: "customer_name", Value: "text"},
+			{Key: "shipping_address", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("order_search").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{
+				{Key: "order_id", Value: orderIDWeight},
+				{Key: "items.sku", Value: 5},
+				{Key: "customer_name", Value: 3},
+				{Key: "shipping_address", Value: 1},
+			}),
+	},
+	{
+		// Used by the archival job, which runs across tenants
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}},
+	},
+}
+
+// archiveIndexes are the indexes of the archive collection. Orders keep their _id when they
+// are archived, which makes re-archiving the same order a no-op. Order IDs are not unique: two
+// tenants may use the same one, and a tenant may reuse the ID of an archived order.
+var archiveIndexes = []mongo.IndexModel{
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "order_id", Value: 1}},
+	},
+}
+
+// legacyArchiveIndex was a unique index on the order ID, which rejected the orders of
+// different tenants sharing an ID
+const legacyArchiveIndex = "order_id_1"
+
+// indexNotFound is the MongoDB error code of a missing index
+const indexNotFound = 27
+
+// EnsureIndexes creates the indexes the repository relies on
+func (r *OrderRepositoryMongoDB) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.collection.Indexes().CreateMany(ctx, orderIndexes); err != nil {
+		r.logger.Error("Failed to create order indexes", zap.Error(err))
+		return err
+	}
+
+	if _, err := r.archive.Indexes().DropOne(ctx, legacyArchiveIndex); err != nil && !isIndexNotFound(err) {
+		r.logger.Error("Failed to drop the legacy archive index", zap.Error(err))
+		return err
+	}
+
+	if _, err := r.archive.Indexes().CreateMany(ctx, archiveIndexes); err != nil {
+		r.logger.Error("Failed to create archive indexes", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// isIndexNotFound reports whether err is the error of dropping a missing index, or an index of
+// a collection that does not exist yet
+func isIndexNotFound(err error) bool {
+	cmdErr, ok := err.(mongo.CommandError)
+	return ok && (cmdErr.Code == indexNotFound || cmdErr.HasErrorMessage("ns not found"))
+}
+
+// resolveSort maps the requested sort keys to a full index order. A sort is only accepted
+// when its keys are a prefix of an order index, with every direction matching the index or
+// every direction reversed, so listings never sort in memory. The remaining index keys are
+// appended as tie-breakers, which keeps the order deterministic.
+func resolveSort(sort []domain.SortField) (bson.D, error) {
+	if len(sort) == 0 {
+		return newestFirst, nil
+	}
+
+	for _, index := range orderIndexes {
+		keys, ok := index.Keys.(bson.D)
+		if !ok || len(keys) < 2 || keys[0].Key != "tenant_id" || keys[len(keys)-1].Key != "_id" {
+			continue
+		}
+		keys = keys[1:]
+		if len(sort) > len(keys) {
+			continue
+		}
+
+		// 1 when the sort follows the index, -1 when it walks it backwards
+		direction := sortDirection(sort[0]) * keys[0].Value.(int)
+		if matchesIndexPrefix(sort, keys, direction) {
+			resolved := make(bson.D, len(keys))
+			for i, key := range keys {
+				resolved[i] = bson.E{Key: key.Key, Value: key.Value.(int) * direction}
+			}
+			return resolved, nil
+		}
+	}
+
+	return nil, errors.ErrUnsupportedSort
+}
+
+func matchesIndexPrefix(sort []domain.SortField, keys bson.D, direction int) bool {
+	for i, field := range sort {
+		if keys[i].Key != field.Field || sortDirection(field) != keys[i].Value.(int)*direction {
+			return false
+		}
+	}
+	return true
+}
+
+func sortDirection(field domain.SortField) int {
+	if field.Descending {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// Migrate backfills the fields the queries rely on in the orders stored before they were
+// introduced. It is idempotent, so it runs on every start, after EnsureIndexes.
+func (r *OrderRepositoryMongoDB) Migrate(ctx context.Context, defaultTenant string) error {
+	for _, collection := range []*mongo.Collection{r.collection, r.archive} {
+		if err := r.backfillTenant(ctx, collection, defaultTenant); err != nil {
+			return err
+		}
+		if err := r.backfillTotal(ctx, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillTenant assigns the orders stored before multi-tenancy, which queries scoped to a
+// tenant never match, to the default tenant. Without one they are left unassigned.
+func (r *OrderRepositoryMongoDB) backfillTenant(ctx context.Context, collection *mongo.Collection, defaultTenant string) error {
+	logger := r.logger.With(zap.String("collection", collection.Name()))
+	// Matches a missing tenant_id too, and is served by the indexes starting with it
+	withoutTenant := bson.M{"tenant_id": nil}
+
+	if defaultTenant == "" {
+		count, err := collection.CountDocuments(ctx, withoutTenant)
+		if err != nil {
+			logger.Error("Failed to count orders without tenant", zap.Error(err))
+			return err
+		}
+		if count > 0 {
+			logger.Warn("Orders without tenant are not served, set TENANT_DEFAULT to assign them", zap.Int64("orders", count))
+		}
+		return nil
+	}
+
+	result, err := collection.UpdateMany(ctx, withoutTenant, bson.M{"$set": bson.M{"tenant_id": defaultTenant}})
+	if err != nil {
+		logger.Error("Failed to assign orders to the default tenant", zap.Error(err))
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		logger.Info("Assigned orders to the default tenant", zap.String("tenant_id", defaultTenant), zap.Int64("orders", result.ModifiedCount))
+	}
+	return nil
+}
+
+// backfillTotal computes the total of the orders stored before it was, which the total
+// filters and sort would otherwise leave out, like Order.CalculateTotal: the sum of price
+// times quantity of the items, rounded to cents
+func (r *OrderRepositoryMongoDB) backfillTotal(ctx context.Context, collection *mongo.Collection) error {
+	total := bson.M{"$round": bson.A{
+		bson.M{"$sum": bson.M{"$map": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$items", bson.A{}}},
+			"in": bson.M{"$multiply": bson.A{
+				bson.M{"$ifNull": bson.A{"$$this.price", 0}},
+				bson.M{"$ifNull": bson.A{"$$this.quantity", 0}},
+			}},
+		}}},
+		2,
+	}}
+
+	result, err := collection.UpdateMany(ctx,
+		bson.M{"total": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"total": total}}}},
+	)
+	if err != nil {
+		r.logger.Error("Failed to compute the order totals", zap.Error(err), zap.String("collection", collection.Name()))
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		r.logger.Info("Computed the order totals", zap.String("collection", collection.Name()), zap.Int64("orders", result.ModifiedCount))
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ArchiveBefore copies a batch of finished orders into the archive collection and then
+// removes from the orders collection the ones found in the archive
+func (r *OrderRepositoryMongoDB) ArchiveBefore(ctx context.Context, statuses []domain.OrderStatus, cutoff time.Time, batchSize int) (int, error) {
+	filter := bson.M{
+		"status":     bson.M{"$in": statuses},
+		"updated_at": bson.M{"$lt": cutoff},
+	}
+	opts := options.Find().
+		SetLimit(int64(batchSize)).
+		SetSort(bson.D{{Key: "updated_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.Error("Failed to find orders to archive", zap.Error(err))
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*domain.Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		r.logger.Error("Failed to decode orders to archive", zap.Error(err))
+		return 0, err
+	}
+	if len(orders) == 0 {
+		return 0, nil
+	}
+
+	archivedAt := time.Now()
+	docs := make([]interface{}, len(orders))
+	ids := make([]primitive.ObjectID, len(orders))
+	for i, order := range orders {
+		order.ArchivedAt = &archivedAt
+		docs[i] = order
+		ids[i] = order.ID
+	}
+
+	// Orders copied by a previous run that failed before deleting them are already in the
+	// archive, so duplicate key errors are expected and safe to ignore
+	_, err = r.archive.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !isOnlyDuplicateKeyErrors(err) {
+		r.logger.Error("Failed to copy orders to archive", zap.Error(err))
+		return 0, err
+	}
+
+	// Only the orders confirmed to be in the archive are removed, so an order the archive
+	// rejected stays in the orders collection
+	archived, err := r.archivedIDs(ctx, ids)
+	if err != nil {
+		r.logger.Error("Failed to confirm the archived orders", zap.Error(err))
+		return 0, err
+	}
+	if len(archived) < len(ids) {
+		r.logger.Warn("Orders missing from the archive were kept", zap.Int("missing", len(ids)-len(archived)))
+	}
+	if len(archived) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": archived}})
+	if err != nil {
+		r.logger.Error("Failed to remove archived orders", zap.Error(err))
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// archivedIDs returns the IDs of the documents found in the archive among ids
+func (r *OrderRepositoryMongoDB) archivedIDs(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := r.archive.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	archived := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		archived[i] = doc.ID
+	}
+	return archived, nil
+}
+
+// isOnlyDuplicateKeyErrors reports whether every write error in err is a duplicate key error
+func isOnlyDuplicateKeyErrors(err error) bool {
+	bulkErr, ok := err.(mongo.BulkWriteException)
+	if !ok || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
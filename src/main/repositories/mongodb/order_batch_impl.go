@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/requestid"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// FindByIDs finds a batch of orders by their IDs in MongoDB, falling back to the archive
+// for the ones that are not in the orders collection
+func (r *OrderRepositoryMongoDB) FindByIDs(ctx context.Context, orderIDs []string, read domain.ReadOptions) ([]*domain.Order, error) {
+	filter, err := tenantFilter(ctx, bson.M{"order_id": bson.M{"$in": orderIDs}})
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().SetProjection(buildProjection(read))
+
+	orders, err := r.find(ctx, filter, findOptions)
+	if err != nil || len(orders) == len(orderIDs) {
+		return orders, err
+	}
+
+	found := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		found[order.OrderID] = true
+	}
+	var missing []string
+	for _, orderID := range orderIDs {
+		if !found[orderID] {
+			missing = append(missing, orderID)
+		}
+	}
+
+	// The missing orders may have been moved to cold storage
+	filter["order_id"] = bson.M{"$in": missing}
+	archived, err := decodeAll(ctx, r.archive, filter, findOptions)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to find archived orders", zap.Error(err))
+		return nil, err
+	}
+
+	return append(orders, archived...), nil
+}
+
+// SummarizeCustomers counts the orders of each customer with their total and last order date
+func (r *OrderRepositoryMongoDB) SummarizeCustomers(ctx context.Context, customerIDs []string) ([]*domain.CustomerSummary, error) {
+	match, err := tenantFilter(ctx, bson.M{"customer_id": bson.M{"$in": customerIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$customer_id",
+			"order_count":   bson.M{"$sum": 1},
+			"total_spent":   bson.M{"$sum": "$total"},
+			"last_order_at": bson.M{"$max": "$created_at"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to summarize customers", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var summaries []*domain.CustomerSummary
+	if err := cursor.All(ctx, &summaries); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to decode customer summaries", zap.Error(err))
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// decodeAll runs a query on a collection and decodes all the matching orders
+func decodeAll(ctx context.Context, collection *mongo.Collection, filter interface{}, opts *options.FindOptions) ([]*domain.Order, error) {
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*domain.Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
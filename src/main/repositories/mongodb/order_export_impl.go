@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/requestid"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is the number of orders fetched from MongoDB at a time during an export
+const exportBatchSize = 500
+
+// Export walks a MongoDB cursor over the orders matching the filter, so only one batch of
+// orders is held in memory whatever the size of the export. Archived orders follow the live
+// ones when the filter includes them, each in the sort order.
+func (r *OrderRepositoryMongoDB) Export(ctx context.Context, filter domain.OrderFilter, sort []domain.SortField, read domain.ReadOptions, each func(*domain.Order) error) error {
+	mongoFilter, err := tenantFilter(ctx, buildListFilter(filter))
+	if err != nil {
+		return err
+	}
+
+	mongoSort, err := resolveSort(sort)
+	if err != nil {
+		return err
+	}
+
+	findOptions := options.Find().
+		SetSort(mongoSort).
+		SetProjection(buildProjection(read)).
+		SetBatchSize(exportBatchSize)
+	if err := r.export(ctx, r.collection, mongoFilter, findOptions, each); err != nil {
+		return err
+	}
+	if !filter.IncludeArchived {
+		return nil
+	}
+
+	// The archive is not indexed for the sorts, so large archives sort on disk
+	return r.export(ctx, r.archive, mongoFilter, findOptions.SetAllowDiskUse(true), each)
+}
+
+// export calls each with the orders of the collection matching the filter
+func (r *OrderRepositoryMongoDB) export(ctx context.Context, collection *mongo.Collection, mongoFilter bson.M, findOptions *options.FindOptions, each func(*domain.Order) error) error {
+	cursor, err := collection.Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to export orders", zap.Error(err))
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var order domain.Order
+		if err := cursor.Decode(&order); err != nil {
+			requestid.Logger(ctx, r.logger).Error("Failed to decode order", zap.Error(err))
+			return err
+		}
+		if err := each(&order); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to export orders", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 
 	domain "order-management-ms/src/main/models/datastore"
 	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,22 +19,30 @@ import (
 // OrderRepositoryMongoDB implements OrderRepository for MongoDB
 type OrderRepositoryMongoDB struct {
 	collection *mongo.Collection
+	archive    *mongo.Collection
 	logger     *zap.Logger
 }
 
 // NewOrderRepository creates a new MongoDB order repository
-func NewOrderRepository(db *mongo.Database, collectionName string, logger *zap.Logger) *OrderRepositoryMongoDB {
+func NewOrderRepository(db *mongo.Database, collectionName, archiveCollectionName string, logger *zap.Logger) *OrderRepositoryMongoDB {
 	return &OrderRepositoryMongoDB{
 		collection: db.Collection(collectionName),
+		archive:    db.Collection(archiveCollectionName),
 		logger:     logger,
 	}
 }
 
 // Create saves a new order to MongoDB
 func (r *OrderRepositoryMongoDB) Create(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errors.ErrMissingTenant
+	}
+	order.TenantID = tenantID
+
 	result, err := r.collection.InsertOne(ctx, order)
 	if err != nil {
-		r.logger.Error("Failed to create order", zap.Error(err))
+		requestid.Logger(ctx, r.logger).Error("Failed to create order", zap.Error(err))
 		return nil, err
 	}
 
@@ -42,7 +52,7 @@ func (r *OrderRepositoryMongoDB) Create(ctx context.Context, order *domain.Order
 	}
 
 	var createdOrder domain.Order
-	err = r.collection.FindOne(ctx, bson.M{"_id": result.InsertedID}).Decode(&createdOrder)
+	err = r.collection.FindOne(ctx, bson.M{"_id": result.InsertedID, "tenant_id": tenantID}).Decode(&createdOrder)
 	if err != nil {
 		return nil, err
 	}
@@ -50,83 +60,278 @@ func (r *OrderRepositoryMongoDB) Create(ctx context.Context, order *domain.Order
 	return &createdOrder, nil
 }
 
-// FindByID finds an order by its ID in MongoDB
-func (r *OrderRepositoryMongoDB) FindByID(ctx context.Context, orderID string) (*domain.Order, error) {
+// FindByID finds an order by its ID in MongoDB, falling back to the archive
+func (r *OrderRepositoryMongoDB) FindByID(ctx context.Context, orderID string, read domain.ReadOptions) (*domain.Order, error) {
+	filter, err := tenantFilter(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+	findOptions := options.FindOne().SetProjection(buildProjection(read))
 
 	var order domain.Order
-	err := r.collection.FindOne(ctx, bson.M{"order_id": orderID}).Decode(&order)
+	err = r.collection.FindOne(ctx, filter, findOptions).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		// The order may have been moved to cold storage
+		err = r.archive.FindOne(ctx, filter, findOptions).Decode(&order)
+	}
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.ErrOrderNotFound
 		}
-		r.logger.Error("Failed to find order", zap.Error(err), zap.String("order_id", orderID))
+		requestid.Logger(ctx, r.logger).Error("Failed to find order", zap.Error(err), zap.String("order_id", orderID))
 		return nil, err
 	}
 
 	return &order, nil
 }
 
-// UpdateStatus updates the status of an order in MongoDB
-func (r *OrderRepositoryMongoDB) UpdateStatus(ctx context.Context, orderID string, status domain.OrderStatus) error {
+// UpdateStatus updates the status of an order in MongoDB, unless another request changed it
+// since it was read
+func (r *OrderRepositoryMongoDB) UpdateStatus(ctx context.Context, orderID string, from, to domain.OrderStatus) error {
+	filter, err := tenantFilter(ctx, bson.M{"order_id": orderID, "status": from})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
+			"status":     to,
+			"updated_at": now,
+		},
+		"$push": bson.M{
+			"status_history": domain.StatusChange{Status: to, ChangedAt: now},
 		},
 	}
 
-	result, err := r.collection.UpdateOne(
-		ctx,
-		bson.M{"order_id": orderID},
-		update,
-	)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 
 	if err != nil {
-		r.logger.Error("Failed to update order status",
+		requestid.Logger(ctx, r.logger).Error("Failed to update order status",
 			zap.Error(err),
 			zap.String("order_id", orderID),
-			zap.String("status", string(status)),
+			zap.String("status", string(to)),
 		)
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.ErrOrderNotFound
+		return errors.ErrInvalidTransition
 	}
 
 	return nil
 }
 
-// List finds all orders, filtered by status and customer ID
-func (r *OrderRepositoryMongoDB) List(ctx context.Context, filter map[string]interface{}, page, limit int) ([]*domain.Order, error) {
-	// Implement pagination
-	skip := (page - 1) * limit
-	options := options.Find().
+// List finds all orders matching the filter
+func (r *OrderRepositoryMongoDB) List(ctx context.Context, filter domain.OrderFilter, opts domain.ListOptions) (*domain.OrderPage, error) {
+	// Build the filter
+	mongoFilter, err := tenantFilter(ctx, buildListFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CursorMode {
+		if !opts.IsDefaultSort() {
+			return nil, errors.ErrCursorSort
+		}
+		return r.listByCursor(ctx, mongoFilter, opts)
+	}
+
+	sort, err := resolveSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Implement pagination, fetching one extra order to know whether there is a next page
+	skip := (opts.Page - 1) * opts.Limit
+	findOptions := options.Find().
 		SetSkip(int64(skip)).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}}) // Sort by creation date descending
+		SetLimit(int64(opts.Limit + 1)).
+		SetSort(sort).
+		SetProjection(buildProjection(opts.Read))
 
-	// Build the filter
-	mongoFilter := bson.M{}
-	if status, ok := filter["status"].(string); ok {
-		mongoFilter["status"] = status
+	orders, err := r.find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, err
 	}
-	if customerID, ok := filter["customer_id"].(string); ok {
-		mongoFilter["customer_id"] = customerID
+
+	hasMore := len(orders) > opts.Limit
+	if hasMore {
+		orders = orders[:opts.Limit]
+	}
+
+	return &domain.OrderPage{Orders: orders, HasMore: hasMore}, nil
+}
+
+// Count counts the orders matching the filter
+func (r *OrderRepositoryMongoDB) Count(ctx context.Context, filter domain.OrderFilter) (int64, error) {
+	mongoFilter, err := tenantFilter(ctx, buildListFilter(filter))
+	if err != nil {
+		return 0, err
 	}
 
-	cursor, err := r.collection.Find(ctx, mongoFilter, options)
+	count, err := r.collection.CountDocuments(ctx, mongoFilter)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to count orders", zap.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// newestFirst is the default ordering of the listings, with _id breaking ties between
+// orders created at the same time
+var newestFirst = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+
+// listByCursor returns the page of orders after (or before, for backward cursors) the
+// cursor position in the (created_at, _id) ordering
+func (r *OrderRepositoryMongoDB) listByCursor(ctx context.Context, mongoFilter bson.M, opts domain.ListOptions) (*domain.OrderPage, error) {
+	cursor := opts.Cursor
+	backward := cursor != nil && cursor.Backward
+
+	// Fetch one extra order to know whether there is another page in the same direction
+	findOptions := options.Find().
+		SetLimit(int64(opts.Limit + 1)).
+		SetProjection(buildProjection(opts.Read))
+	if backward {
+		findOptions.SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	} else {
+		findOptions.SetSort(newestFirst)
+	}
+
+	if cursor != nil {
+		op := "$lt"
+		if backward {
+			op = "$gt"
+		}
+		mongoFilter = bson.M{"$and": bson.A{
+			mongoFilter,
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{op: cursor.CreatedAt}},
+				bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{op: cursor.ID}},
+			}},
+		}}
+	}
+
+	orders, err := r.find(ctx, mongoFilter, findOptions)
 	if err != nil {
-		r.logger.Error("Failed to list orders", zap.Error(err))
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var orders []*domain.Order
-	if err := cursor.All(ctx, &orders); err != nil {
-		r.logger.Error("Failed to decode orders", zap.Error(err))
+	hasMore := len(orders) > opts.Limit
+	if hasMore {
+		orders = orders[:opts.Limit]
+	}
+	if backward {
+		// Backward pages are read oldest first, flip them back to the listing order
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+
+	page := &domain.OrderPage{Orders: orders}
+	if len(orders) == 0 {
+		return page, nil
+	}
+
+	first, last := orders[0], orders[len(orders)-1]
+	if hasMore || backward {
+		page.NextCursor = domain.NewCursor(last, false)
+		page.HasMore = true
+	}
+	if (hasMore && backward) || (!backward && cursor != nil) {
+		page.PrevCursor = domain.NewCursor(first, true)
+	}
+
+	return page, nil
+}
+
+// find runs a query and decodes all the matching orders
+func (r *OrderRepositoryMongoDB) find(ctx context.Context, filter interface{}, opts *options.FindOptions) ([]*domain.Order, error) {
+	orders, err := decodeAll(ctx, r.collection, filter, opts)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to list orders", zap.Error(err))
 		return nil, err
 	}
 
 	return orders, nil
 }
+
+// buildProjection translates the read options into a MongoDB projection, so unrequested
+// fields are never read. The keys used by the pagination cursors are always included.
+func buildProjection(read domain.ReadOptions) bson.M {
+	expandHistory := read.Expands(domain.ExpandHistory)
+
+	if len(read.Fields) == 0 {
+		if expandHistory {
+			return nil
+		}
+		return bson.M{"status_history": 0}
+	}
+
+	projection := bson.M{"tenant_id": 1, "created_at": 1}
+	for _, field := range read.Fields {
+		projection[field] = 1
+	}
+	if expandHistory {
+		projection["status_history"] = 1
+	}
+	return projection
+}
+
+// buildListFilter translates an order filter into a MongoDB query
+func buildListFilter(filter domain.OrderFilter) bson.M {
+	mongoFilter := bson.M{}
+
+	switch len(filter.Statuses) {
+	case 0:
+	case 1:
+		mongoFilter["status"] = filter.Statuses[0]
+	default:
+		mongoFilter["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.CustomerID != "" {
+		mongoFilter["customer_id"] = filter.CustomerID
+	}
+	if filter.SKU != "" {
+		mongoFilter["items.sku"] = filter.SKU
+	}
+	if rng := rangeFilter(filter.CreatedFrom, filter.CreatedTo); rng != nil {
+		mongoFilter["created_at"] = rng
+	}
+	if rng := rangeFilter(filter.UpdatedFrom, filter.UpdatedTo); rng != nil {
+		mongoFilter["updated_at"] = rng
+	}
+	if rng := rangeFilter(filter.MinTotal, filter.MaxTotal); rng != nil {
+		mongoFilter["total"] = rng
+	}
+
+	return mongoFilter
+}
+
+// rangeFilter builds an inclusive range condition, or nil when both bounds are unset
+func rangeFilter[T any](from, to *T) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+
+	rng := bson.M{}
+	if from != nil {
+		rng["$gte"] = *from
+	}
+	if to != nil {
+		rng["$lte"] = *to
+	}
+	return rng
+}
+
+// tenantFilter scopes a query to the tenant in the context, so a query can never match
+// another tenant's orders
+func tenantFilter(ctx context.Context, filter bson.M) (bson.M, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errors.ErrMissingTenant
+	}
+	filter["tenant_id"] = tenantID
+	return filter, nil
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/search"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// orderIDWeight is the text index weight of the order ID, also used to score partial order ID matches
+const orderIDWeight = 10
+
+// orderIDFragment matches queries that may be part of an order ID, e.g. "e7825d"
+var orderIDFragment = regexp.MustCompile(`^[A-Za-z0-9-]{3,}$`)
+
+// scoredOrder is an order decoded with its text search score
+type scoredOrder struct {
+	domain.Order `bson:",inline"`
+	Score        float64 `bson:"score"`
+}
+
+// Search finds orders with the text index, ranked by text score. Text search only matches
+// whole words, so queries that look like part of an order ID are also matched against the
+// order IDs directly.
+func (r *OrderRepositoryMongoDB) Search(ctx context.Context, query string, limit int) ([]*domain.SearchHit, error) {
+	filter, err := tenantFilter(ctx, bson.M{"$text": bson.M{"$search": query}})
+	if err != nil {
+		return nil, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to search orders", zap.Error(err), zap.String("query", query))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var scored []*scoredOrder
+	if err := cursor.All(ctx, &scored); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to decode search results", zap.Error(err))
+		return nil, err
+	}
+
+	hits := make(map[string]*domain.SearchHit, len(scored))
+	for _, s := range scored {
+		order := s.Order
+		hits[order.OrderID] = &domain.SearchHit{Order: &order, Score: s.Score}
+	}
+
+	if orderIDFragment.MatchString(query) {
+		if err := r.searchOrderIDs(ctx, query, limit, hits); err != nil {
+			return nil, err
+		}
+	}
+
+	return rankHits(hits, search.Terms(query), limit), nil
+}
+
+// searchOrderIDs adds the orders whose ID contains the query, scored by how much of the ID matched
+func (r *OrderRepositoryMongoDB) searchOrderIDs(ctx context.Context, query string, limit int, hits map[string]*domain.SearchHit) error {
+	filter, err := tenantFilter(ctx, bson.M{
+		"order_id": primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"},
+	})
+	if err != nil {
+		return err
+	}
+
+	orders, err := r.find(ctx, filter, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		score := orderIDWeight * float64(len(query)) / float64(len(order.OrderID))
+		if hit, ok := hits[order.OrderID]; ok {
+			hit.Score += score
+			continue
+		}
+		hits[order.OrderID] = &domain.SearchHit{Order: order, Score: score}
+	}
+
+	return nil
+}
+
+// rankHits sorts the hits by score, keeps the best ones and highlights their matching fields
+func rankHits(hits map[string]*domain.SearchHit, terms []string, limit int) []*domain.SearchHit {
+	ranked := make([]*domain.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		ranked = append(ranked, hit)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Order.OrderID < ranked[j].Order.OrderID
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	for _, hit := range ranked {
+		hit.Highlights = search.HighlightOrder(hit.Order, terms)
+	}
+	return ranked
+}
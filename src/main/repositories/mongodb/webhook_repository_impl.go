@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// maxDeliveryLog is the number of attempts kept in the log of a delivery
+const maxDeliveryLog = 20
+
+// webhookIndexes are the indexes of the webhooks collection. The delivery worker looks
+// webhooks up by their globally unique ID, the event dispatch and listings by tenant.
+var webhookIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "webhook_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+	{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "event_types", Value: 1}, {Key: "created_at", Value: -1}},
+	},
+}
+
+// deliveryIndexes are the indexes of the deliveries collection. Deliveries expire retention
+// after they were created.
+func deliveryIndexes(retention time.Duration) []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "delivery_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Used by the delivery worker, which runs across tenants
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	}
+}
+
+// WebhookRepositoryMongoDB implements WebhookRepository for MongoDB
+type WebhookRepositoryMongoDB struct {
+	collection *mongo.Collection
+	deliveries *mongo.Collection
+	retention  time.Duration
+	logger     *zap.Logger
+}
+
+// NewWebhookRepository creates a new MongoDB webhook repository, keeping the deliveries for
+// retention
+func NewWebhookRepository(db *mongo.Database, collectionName, deliveriesCollectionName string, retention time.Duration, logger *zap.Logger) *WebhookRepositoryMongoDB {
+	return &WebhookRepositoryMongoDB{
+		collection: db.Collection(collectionName),
+		deliveries: db.Collection(deliveriesCollectionName),
+		retention:  retention,
+		logger:     logger,
+	}
+}
+
+// EnsureIndexes creates the indexes the repository relies on
+func (r *WebhookRepositoryMongoDB) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.collection.Indexes().CreateMany(ctx, webhookIndexes); err != nil {
+		r.logger.Error("Failed to create webhook indexes", zap.Error(err))
+		return err
+	}
+
+	if _, err := r.deliveries.Indexes().CreateMany(ctx, deliveryIndexes(r.retention)); err != nil {
+		r.logger.Error("Failed to create webhook delivery indexes", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CreateWebhook saves a new webhook for the tenant in the context
+func (r *WebhookRepositoryMongoDB) CreateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errors.ErrMissingTenant
+	}
+	webhook.TenantID = tenantID
+
+	if _, err := r.collection.InsertOne(ctx, webhook); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to create webhook", zap.Error(err), zap.String("webhook_id", webhook.WebhookID))
+		return err
+	}
+	return nil
+}
+
+// ListWebhooks returns the webhooks of the tenant in the context, newest first, optionally
+// only those subscribed to eventType
+func (r *WebhookRepositoryMongoDB) ListWebhooks(ctx context.Context, eventType string) ([]*domain.Webhook, error) {
+	filter, err := tenantFilter(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if eventType != "" {
+		filter["event_types"] = eventType
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to list webhooks", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []*domain.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to decode webhooks", zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// FindWebhook finds a webhook by its ID across tenants, for the delivery worker
+func (r *WebhookRepositoryMongoDB) FindWebhook(ctx context.Context, webhookID string) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.collection.FindOne(ctx, bson.M{"webhook_id": webhookID}).Decode(&webhook)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrWebhookNotFound
+		}
+		requestid.Logger(ctx, r.logger).Error("Failed to find webhook", zap.Error(err), zap.String("webhook_id", webhookID))
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook deletes a webhook of the tenant in the context
+func (r *WebhookRepositoryMongoDB) DeleteWebhook(ctx context.Context, webhookID string) error {
+	filter, err := tenantFilter(ctx, bson.M{"webhook_id": webhookID})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to delete webhook", zap.Error(err), zap.String("webhook_id", webhookID))
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// CreateDeliveries saves new deliveries
+func (r *WebhookRepositoryMongoDB) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		documents[i] = delivery
+	}
+	if _, err := r.deliveries.InsertMany(ctx, documents); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to create webhook deliveries", zap.Error(err), zap.Int("count", len(deliveries)))
+		return err
+	}
+	return nil
+}
+
+// ClaimDeliveries claims the pending deliveries due at now, oldest first, one at a time so
+// concurrent workers never claim the same delivery
+func (r *WebhookRepositoryMongoDB) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	filter := bson.M{"status": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	claimed := []*domain.WebhookDelivery{}
+	for len(claimed) < limit {
+		var delivery domain.WebhookDelivery
+		if err := r.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery); err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			r.logger.Error("Failed to claim webhook delivery", zap.Error(err))
+			return claimed, err
+		}
+		claimed = append(claimed, &delivery)
+	}
+	return claimed, nil
+}
+
+// RecordAttempt saves the outcome of an attempt, keeping the last attempts in the log
+func (r *WebhookRepositoryMongoDB) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt domain.DeliveryAttempt) error {
+	set := bson.M{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+	}
+	if delivery.DeliveredAt != nil {
+		set["delivered_at"] = delivery.DeliveredAt
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"log": bson.M{"$each": bson.A{attempt}, "$slice": -maxDeliveryLog}},
+	}
+
+	if _, err := r.deliveries.UpdateOne(ctx, bson.M{"delivery_id": delivery.DeliveryID}, update); err != nil {
+		r.logger.Error("Failed to record webhook delivery attempt", zap.Error(err), zap.String("delivery_id", delivery.DeliveryID))
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a webhook of the tenant in the context, newest first
+func (r *WebhookRepositoryMongoDB) ListDeliveries(ctx context.Context, webhookID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	filter, err := tenantFilter(ctx, bson.M{"webhook_id": webhookID})
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.deliveries.Find(ctx, filter, opts)
+	if err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to list webhook deliveries", zap.Error(err), zap.String("webhook_id", webhookID))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []*domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		requestid.Logger(ctx, r.logger).Error("Failed to decode webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedeliverDelivery makes a delivery of a webhook of the tenant in the context pending and
+// due now, resetting its attempts. Its log is kept.
+func (r *WebhookRepositoryMongoDB) RedeliverDelivery(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error) {
+	filter, err := tenantFilter(ctx, bson.M{"webhook_id": webhookID, "delivery_id": deliveryID})
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":          domain.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		},
+		"$unset": bson.M{"delivered_at": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var delivery domain.WebhookDelivery
+	if err := r.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		requestid.Logger(ctx, r.logger).Error("Failed to redeliver webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID))
+		return nil, err
+	}
+	return &delivery, nil
+}
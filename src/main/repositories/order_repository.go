@@ -10,12 +10,26 @@ import (
 type OrderRepository interface {
 	// Create saves a new order to the database
 	Create(ctx context.Context, order *domain.Order) (*domain.Order, error)
-	// FindByID finds an order by its order ID
-	FindByID(ctx context.Context, orderID string) (*domain.Order, error)
+	// FindByID finds an order by its order ID, reading the parts selected by the options
+	FindByID(ctx context.Context, orderID string, read domain.ReadOptions) (*domain.Order, error)
 
-	// UpdateStatus updates the status of an order
-	UpdateStatus(ctx context.Context, orderID string, status domain.OrderStatus) error
+	// FindByIDs finds the orders with the given order IDs, skipping the ones that do not exist
+	FindByIDs(ctx context.Context, orderIDs []string, read domain.ReadOptions) ([]*domain.Order, error)
 
-	// List returns a list of orders with pagination and filtering
-	List(ctx context.Context, filter map[string]interface{}, page, limit int) ([]*domain.Order, error)
+	// UpdateStatus moves an order from one status to another and records the change in its
+	// history. It fails with ErrInvalidTransition when the order is no longer in the from status.
+	UpdateStatus(ctx context.Context, orderID string, from, to domain.OrderStatus) error
+
+	// List returns a page of orders with pagination and filtering
+	List(ctx context.Context, filter domain.OrderFilter, opts domain.ListOptions) (*domain.OrderPage, error)
+
+	// Count returns the number of orders matching the filter
+	Count(ctx context.Context, filter domain.OrderFilter) (int64, error)
+
+	// Export calls each with every order matching the filter, in the sort order, reading
+	// them from the database as it goes. It stops at the first error of each.
+	Export(ctx context.Context, filter domain.OrderFilter, sort []domain.SortField, read domain.ReadOptions, each func(*domain.Order) error) error
+
+	// SummarizeCustomers aggregates the orders of each of the given customers
+	SummarizeCustomers(ctx context.Context, customerIDs []string) ([]*domain.CustomerSummary, error)
 }
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	domain "order-management-ms/src/main/models/datastore"
+)
+
+// SearchIndex defines the interface for full-text search over orders
+type SearchIndex interface {
+	// Search returns up to limit orders matching the query, most relevant first
+	Search(ctx context.Context, query string, limit int) ([]*domain.SearchHit, error)
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+)
+
+// WebhookRepository defines the interface for webhook and delivery data access. The
+// management operations are scoped to the tenant in the context; the delivery worker claims
+// and records deliveries across tenants.
+type WebhookRepository interface {
+	// CreateWebhook saves a new webhook
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook) error
+
+	// ListWebhooks returns the webhooks of the tenant, newest first. When eventType is set,
+	// only the webhooks subscribed to it are returned.
+	ListWebhooks(ctx context.Context, eventType string) ([]*domain.Webhook, error)
+
+	// FindWebhook finds a webhook by its ID, whatever its tenant
+	FindWebhook(ctx context.Context, webhookID string) (*domain.Webhook, error)
+
+	// DeleteWebhook deletes a webhook. Its pending deliveries fail on their next attempt.
+	DeleteWebhook(ctx context.Context, webhookID string) error
+
+	// CreateDeliveries saves new deliveries
+	CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+
+	// ClaimDeliveries returns up to limit pending deliveries due at now, postponing their next
+	// attempt to leaseUntil so other workers skip them while they are attempted
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error)
+
+	// RecordAttempt saves the status, attempts, next attempt and delivery time of a delivery,
+	// and appends the attempt to its log
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt domain.DeliveryAttempt) error
+
+	// ListDeliveries returns the deliveries of a webhook of the tenant, newest first,
+	// optionally only those with the status
+	ListDeliveries(ctx context.Context, webhookID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+
+	// RedeliverDelivery makes a delivery of a webhook of the tenant pending again, with a new
+	// budget of attempts
+	RedeliverDelivery(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error)
+}
@@ -0,0 +1,232 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	modelsv2 "order-management-ms/src/main/models/api/v2"
+	domain "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// keyPrefix starts every API key, so leaked keys are easy to recognize
+const keyPrefix = "omk_"
+
+// Service defines the interface for API key operations
+type Service interface {
+	IssueKey(ctx context.Context, key *domain.APIKey) (*modelsv2.APIKeyResponse, error)
+	ListKeys(ctx context.Context) (*modelsv2.ListAPIKeysResponse, error)
+	RotateKey(ctx context.Context, keyID string) (*modelsv2.APIKeyResponse, error)
+	RevokeKey(ctx context.Context, keyID string) error
+	Authenticate(ctx context.Context, plainKey, clientIP string) (*domain.APIKey, error)
+}
+
+// IssueKey creates an API key for the tenant in the context. The returned response is the
+// only one carrying the plain key.
+func (s *APIKeyService) IssueKey(ctx context.Context, key *domain.APIKey) (*modelsv2.APIKeyResponse, error) {
+	if err := authz.Require(ctx, authz.ManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	keyID, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	plainKey, hash, err := newSecret(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	key.KeyID = keyID
+	key.Hash = hash
+	key.CreatedAt = time.Now()
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to create API key", zap.Error(err), zap.String("key_id", keyID))
+		return nil, err
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Issued API key", zap.String("key_id", keyID), zap.Strings("scopes", key.Scopes))
+	return modelsv2.NewAPIKeyResponse(key, plainKey), nil
+}
+
+// ListKeys returns the API keys of the tenant in the context, without their secrets
+func (s *APIKeyService) ListKeys(ctx context.Context) (*modelsv2.ListAPIKeysResponse, error) {
+	if err := authz.Require(ctx, authz.ManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListKeys(ctx)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to list API keys", zap.Error(err))
+		return nil, err
+	}
+	return modelsv2.NewListAPIKeysResponse(keys), nil
+}
+
+// RotateKey replaces the secret of an API key. The previous key keeps working for the
+// configured grace period, so partners can roll the new one out.
+func (s *APIKeyService) RotateKey(ctx context.Context, keyID string) (*modelsv2.APIKeyResponse, error) {
+	if err := authz.Require(ctx, authz.ManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	plainKey, hash, err := newSecret(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.repo.RotateKey(ctx, keyID, hash, time.Now().Add(s.cfg.RotationGrace))
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to rotate API key", zap.Error(err), zap.String("key_id", keyID))
+		return nil, fmt.Errorf("rotate API key %s: %w", keyID, err)
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Rotated API key", zap.String("key_id", keyID))
+	return modelsv2.NewAPIKeyResponse(key, plainKey), nil
+}
+
+// RevokeKey revokes an API key, and its previous secret, immediately
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID string) error {
+	if err := authz.Require(ctx, authz.ManageAPIKeys); err != nil {
+		return err
+	}
+
+	if err := s.repo.RevokeKey(ctx, keyID); err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to revoke API key", zap.Error(err), zap.String("key_id", keyID))
+		return fmt.Errorf("revoke API key %s: %w", keyID, err)
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Revoked API key", zap.String("key_id", keyID))
+	return nil
+}
+
+// Authenticate checks a plain key sent from clientIP and returns the stored key. Unknown,
+// revoked and expired keys are all rejected with ErrInvalidAPIKey, so callers cannot tell
+// them apart.
+func (s *APIKeyService) Authenticate(ctx context.Context, plainKey, clientIP string) (*domain.APIKey, error) {
+	keyID, ok := parseKeyID(plainKey)
+	if !ok {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.FindKey(ctx, keyID)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrAPIKeyNotFound) {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("find API key %s: %w", keyID, err)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || !matchesHash(key, hashKey(plainKey), now) {
+		return nil, errors.ErrInvalidAPIKey
+	}
+	if !allowsIP(key.AllowedIPs, clientIP) {
+		requestid.Logger(ctx, s.logger).Warn("API key used from a disallowed IP address", zap.String("key_id", keyID), zap.String("ip", clientIP))
+		return nil, errors.ErrIPNotAllowed
+	}
+
+	// The last use is only recorded every interval, so busy keys do not write on every request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= s.cfg.LastUsedInterval {
+		if err := s.repo.TouchKey(ctx, keyID, now); err != nil {
+			requestid.Logger(ctx, s.logger).Warn("Failed to record the last use of an API key", zap.Error(err), zap.String("key_id", keyID))
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+// Permissions returns the permissions granted by the scopes of an API key. Scopes that are
+// not API key scopes grant nothing.
+func Permissions(key *domain.APIKey) []authz.Permission {
+	var permissions []authz.Permission
+	for _, scope := range key.Scopes {
+		if slices.Contains(authz.APIKeyScopes, authz.Permission(scope)) {
+			permissions = append(permissions, authz.Permission(scope))
+		}
+	}
+	return permissions
+}
+
+// newSecret generates a plain key for the key ID, "omk_<key ID>_<secret>", and its hash
+func newSecret(keyID string) (string, string, error) {
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", "", err
+	}
+	plainKey := keyPrefix + keyID + "_" + secret
+	return plainKey, hashKey(plainKey), nil
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+	return encode(data), nil
+}
+
+// hashKey hashes a plain key. Keys carry 256 random bits, so a fast hash is enough.
+func hashKey(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseKeyID extracts the key ID of a plain key. Key IDs are hexadecimal, so the first
+// underscore after the prefix ends them.
+func parseKeyID(plainKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(plainKey, keyPrefix)
+	if !ok {
+		return "", false
+	}
+	keyID, secret, ok := strings.Cut(rest, "_")
+	return keyID, ok && keyID != "" && secret != ""
+}
+
+// matchesHash checks the hash against the current hash of the key, or its previous hash
+// during the grace period of a rotation
+func matchesHash(key *domain.APIKey, hash string, now time.Time) bool {
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) == 1 {
+		return true
+	}
+	return key.PreviousHash != "" && key.PreviousExpiresAt != nil && now.Before(*key.PreviousExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(key.PreviousHash), []byte(hash)) == 1
+}
+
+// allowsIP checks the client IP against the addresses and CIDR ranges of the allow-list.
+// An empty allow-list allows every address.
+func allowsIP(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+
+	for _, entry := range allowed {
+		if prefix, err := netip.ParsePrefix(entry); err == nil && prefix.Contains(ip) {
+			return true
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil && addr.Unmap() == ip {
+			return true
+		}
+	}
+	return false
+}
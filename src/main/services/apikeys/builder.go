@@ -0,0 +1,22 @@
+package apikeys
+
+import (
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/repositories"
+
+	"go.uber.org/zap"
+)
+
+type APIKeyService struct {
+	repo   repositories.APIKeyRepository
+	cfg    config.APIKeys
+	logger *zap.Logger
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository, cfg config.APIKeys, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+
+	"go.uber.org/zap"
+)
+
+// Run archives finished orders every configured interval until the context is cancelled
+func (s *ArchiveService) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		s.logger.Info("Order archival is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ArchiveOrders(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to archive orders", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveOrders moves every delivered or cancelled order older than the retention period
+// to the archive, one batch at a time
+func (s *ArchiveService) ArchiveOrders(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.cfg.Retention)
+
+	total := 0
+	for {
+		archived, err := s.repo.ArchiveBefore(ctx, domain.ArchivableStatuses, cutoff, s.cfg.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += archived
+
+		// A short batch means there is nothing left to archive
+		if archived == 0 || archived < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Archived orders",
+			zap.Int("count", total),
+			zap.Time("cutoff", cutoff),
+		)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,22 @@
+package archive
+
+import (
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/repositories"
+
+	"go.uber.org/zap"
+)
+
+type ArchiveService struct {
+	repo   repositories.ArchiveRepository
+	cfg    config.Archive
+	logger *zap.Logger
+}
+
+func NewArchiveService(repo repositories.ArchiveRepository, cfg config.Archive, logger *zap.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
@@ -11,14 +11,16 @@ import (
 
 type OrderService struct {
 	repo           repositories.OrderRepository
+	searchIndex    repositories.SearchIndex
 	logger         *zap.Logger
 	cache          cache.Repository
 	eventPublisher kafkaDto.EventPublisher
 }
 
-func NewOrderService(repo repositories.OrderRepository, logger *zap.Logger, cache cache.Repository, eventPublisher kafkaDto.EventPublisher) *OrderService {
+func NewOrderService(repo repositories.OrderRepository, searchIndex repositories.SearchIndex, logger *zap.Logger, cache cache.Repository, eventPublisher kafkaDto.EventPublisher) *OrderService {
 	return &OrderService{
 		repo:           repo,
+		searchIndex:    searchIndex,
 		logger:         logger,
 		cache:          cache,
 		eventPublisher: eventPublisher,
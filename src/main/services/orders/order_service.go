@@ -3,10 +3,14 @@ package orders
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	models "order-management-ms/src/main/models/api"
 	domain "order-management-ms/src/main/models/datastore"
 	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/authz"
 	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
 	"time"
 
 	"go.uber.org/zap"
@@ -15,80 +19,171 @@ import (
 // Service defines the interface for order operations
 type Service interface {
 	CreateOrder(ctx context.Context, order *domain.Order) (*models.OrderResponse, error)
-	GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error)
-	ListOrders(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*models.OrderResponse, error)
+	GetOrder(ctx context.Context, orderID string, read domain.ReadOptions) (*models.OrderResponse, error)
+	ListOrders(ctx context.Context, filter domain.OrderFilter, opts domain.ListOptions) (*models.ListOrdersResponse, error)
+	ExportOrders(ctx context.Context, filter domain.OrderFilter, sort []domain.SortField, read domain.ReadOptions, write func(*models.OrderResponse) error) error
 	UpdateOrderStatus(ctx context.Context, orderID string, newStatus domain.OrderStatus) error
+	SearchOrders(ctx context.Context, query string, limit int) (*models.SearchOrdersResponse, error)
+	GetOrdersByIDs(ctx context.Context, orderIDs []string, read domain.ReadOptions) ([]*models.OrderResponse, error)
+	GetCustomerSummaries(ctx context.Context, customerIDs []string) ([]*models.CustomerSummaryResponse, error)
 }
 
 // CreateOrder creates a new order
 func (s *OrderService) CreateOrder(ctx context.Context, order *domain.Order) (*models.OrderResponse, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errors.ErrMissingTenant
+	}
+
+	// Customers may only order for themselves
+	customerID, err := authz.WriteScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if customerID != "" && order.CustomerID != customerID {
+		return nil, errors.ErrForbidden
+	}
+
 	// Set default values
+	order.TenantID = tenantID
 	order.Status = domain.StatusNew
+	order.Total = order.CalculateTotal()
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
+	order.StatusHistory = []domain.StatusChange{{Status: domain.StatusNew, ChangedAt: order.CreatedAt}}
 
 	// Save to database
 	newOrder, err := s.repo.Create(ctx, order)
 	if err != nil {
-		s.logger.Error("Failed to create order", zap.Error(err), zap.String("order_id", order.ID.Hex()))
+		requestid.Logger(ctx, s.logger).Error("Failed to create order", zap.Error(err), zap.String("order_id", order.ID.Hex()))
 		return nil, err
 	}
 
 	return models.NewOrderResponse(newOrder), nil
 }
 
-// GetOrder retrieves an order by ID with caching
-func (s *OrderService) GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+// GetOrder retrieves an order by ID with caching. Only full reads are cached; sparse or
+// expanded reads always go to the database, which projects the requested fields along with
+// the ones versioning the order, so every response can be validated by its ETag. The orders
+// of other customers are not found for callers limited to their own orders.
+func (s *OrderService) GetOrder(ctx context.Context, orderID string, read domain.ReadOptions) (*models.OrderResponse, error) {
+	customerID, err := authz.ReadScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try to get from cache first
-	cachedOrder, err := s.getFromCache(ctx, orderID)
-	if err == nil && cachedOrder != nil {
-		return cachedOrder, nil
+	if read.IsDefault() {
+		cachedOrder, err := s.getFromCache(ctx, orderID)
+		if err == nil && cachedOrder != nil {
+			if customerID != "" && cachedOrder.CustomerID != customerID {
+				return nil, errors.ErrOrderNotFound
+			}
+			return cachedOrder, nil
+		}
 	}
 
 	// If not in cache, get from database
-	order, err := s.repo.FindByID(ctx, orderID)
+	order, err := s.repo.FindByID(ctx, orderID, withVersion(withCustomer(read, customerID)))
 	if err != nil {
-		s.logger.Error("Failed to find order",
+		requestid.Logger(ctx, s.logger).Error("Failed to find order",
 			zap.Error(err),
 			zap.String("order_id", orderID),
 		)
+		return nil, fmt.Errorf("find order %s: %w", orderID, err)
+	}
+	if customerID != "" && order.CustomerID != customerID {
 		return nil, errors.ErrOrderNotFound
 	}
 
-	return models.NewOrderResponse(order), nil
+	// Cache full reads, so the next ones, and the conditional ones of the pollers, are
+	// answered without the database
+	if read.IsDefault() {
+		if err := s.SaveOrderInCache(ctx, order); err != nil {
+			requestid.Logger(ctx, s.logger).Warn("Failed to cache order", zap.Error(err), zap.String("order_id", orderID))
+		}
+	}
+
+	return models.NewOrderResponseWith(order, read), nil
 }
 
-// ListOrders retrieves a list of orders with optional filters
-func (s *OrderService) ListOrders(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*models.OrderResponse, error) {
-	orders, err := s.repo.List(ctx, filters, page, limit)
+// ListOrders retrieves a list of orders with optional filters. Callers limited to their own
+// orders only list the orders of their customer, whatever customer they filter on.
+func (s *OrderService) ListOrders(ctx context.Context, filter domain.OrderFilter, opts domain.ListOptions) (*models.ListOrdersResponse, error) {
+	customerID, err := authz.ReadScope(ctx)
 	if err != nil {
-		s.logger.Error("Failed to list orders", zap.Error(err))
 		return nil, err
 	}
+	if customerID != "" {
+		filter.CustomerID = customerID
+	}
 
-	var orderResponses []*models.OrderResponse
-	for _, order := range orders {
-		orderResponses = append(orderResponses, models.NewOrderResponse(order))
+	page, err := s.repo.List(ctx, filter, opts)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to list orders", zap.Error(err))
+		return nil, err
+	}
+
+	if !opts.SkipTotal {
+		total, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			requestid.Logger(ctx, s.logger).Error("Failed to count orders", zap.Error(err))
+			return nil, err
+		}
+		page.Total = &total
 	}
 
-	return orderResponses, nil
+	return models.NewListOrdersResponse(page, opts), nil
 }
 
-// UpdateOrderStatus updates the status of an order
+// ExportOrders calls write with every order matching the filter, in the sort order, as they
+// are read from the database. Customers only export their own orders. Errors of write stop
+// the export and are returned as is.
+func (s *OrderService) ExportOrders(ctx context.Context, filter domain.OrderFilter, sort []domain.SortField, read domain.ReadOptions, write func(*models.OrderResponse) error) error {
+	customerID, err := authz.ReadScope(ctx)
+	if err != nil {
+		return err
+	}
+	if customerID != "" {
+		filter.CustomerID = customerID
+	}
+
+	var writeErr error
+	err = s.repo.Export(ctx, filter, sort, read, func(order *domain.Order) error {
+		writeErr = write(models.NewOrderResponseWith(order, read))
+		return writeErr
+	})
+	if err != nil && writeErr == nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to export orders", zap.Error(err))
+	}
+	return err
+}
+
+// UpdateOrderStatus updates the status of an order. Delivering an order needs its own
+// permission.
 func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, newStatus domain.OrderStatus) error {
+	if err := authz.Require(ctx, authz.UpdateStatus); err != nil {
+		return err
+	}
+	if newStatus == domain.StatusDelivered {
+		if err := authz.Require(ctx, authz.DeliverOrders); err != nil {
+			return err
+		}
+	}
+
 	// Get current order
-	order, err := s.repo.FindByID(ctx, orderID)
+	order, err := s.repo.FindByID(ctx, orderID, domain.ReadOptions{})
 	if err != nil {
-		s.logger.Error("Failed to find order",
+		requestid.Logger(ctx, s.logger).Error("Failed to find order",
 			zap.Error(err),
 			zap.String("order_id", orderID),
 		)
-		return errors.ErrOrderNotFound
+		return fmt.Errorf("find order %s: %w", orderID, err)
 	}
 
 	// Validate status transition
 	if !isValidStatusTransition(order.Status, newStatus) {
-		s.logger.Warn("Invalid status transition",
+		requestid.Logger(ctx, s.logger).Warn("Invalid status transition",
 			zap.String("order_id", orderID),
 			zap.String("current_status", string(order.Status)),
 			zap.String("new_status", string(newStatus)),
@@ -99,24 +194,20 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, ne
 	// Save old status for event
 	oldStatus := order.Status
 
-	// Update status
-	order.Status = newStatus
-	order.UpdatedAt = time.Now()
-
 	// Save to database
-	if err := s.repo.UpdateStatus(ctx, orderID, newStatus); err != nil {
-		s.logger.Error("Failed to update order status",
+	if err := s.repo.UpdateStatus(ctx, orderID, oldStatus, newStatus); err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to update order status",
 			zap.Error(err),
 			zap.String("order_id", orderID),
 			zap.String("new_status", string(newStatus)),
 		)
-		return errors.ErrInternalServer
+		return fmt.Errorf("update status of order %s: %w", orderID, err)
 	}
 
-	event := kafkaDto.NewOrderStatusChangedEvent(orderID, oldStatus, newStatus)
+	event := kafkaDto.NewOrderStatusChangedEvent(orderID, order.CustomerID, oldStatus, newStatus)
 
 	if err := s.eventPublisher.PublishOrderStatusChanged(ctx, event); err != nil {
-		s.logger.Error("Failed to publish order status changed event",
+		requestid.Logger(ctx, s.logger).Error("Failed to publish order status changed event",
 			zap.Error(err),
 			zap.String("order_id", orderID),
 			zap.Any("event", event),
@@ -124,9 +215,9 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, ne
 	}
 
 	// Invalidate cache for this order
-	cacheKey := "order:" + orderID
+	cacheKey := orderCacheKey(ctx, orderID)
 	if err := s.cache.Delete(ctx, cacheKey); err != nil {
-		s.logger.Error("Failed to invalidate cache for order",
+		requestid.Logger(ctx, s.logger).Error("Failed to invalidate cache for order",
 			zap.Error(err),
 			zap.String("order_id", orderID),
 		)
@@ -135,13 +226,78 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, ne
 	return nil
 }
 
+// SearchOrders finds the orders matching a free text query, most relevant first. The search
+// spans every customer, so it needs the permission to read all orders.
+func (s *OrderService) SearchOrders(ctx context.Context, query string, limit int) (*models.SearchOrdersResponse, error) {
+	if err := authz.Require(ctx, authz.ReadOrders); err != nil {
+		return nil, err
+	}
+
+	hits, err := s.searchIndex.Search(ctx, query, limit)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to search orders", zap.Error(err), zap.String("query", query))
+		return nil, err
+	}
+
+	return models.NewSearchOrdersResponse(query, hits), nil
+}
+
+// GetOrdersByIDs retrieves a batch of orders in a single query. The orders that do not exist
+// are left out, like the orders of other customers for callers limited to their own orders,
+// so the result may be shorter than the IDs and is not in their order.
+func (s *OrderService) GetOrdersByIDs(ctx context.Context, orderIDs []string, read domain.ReadOptions) ([]*models.OrderResponse, error) {
+	customerID, err := authz.ReadScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.repo.FindByIDs(ctx, orderIDs, withCustomer(read, customerID))
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to find orders", zap.Error(err), zap.Strings("order_ids", orderIDs))
+		return nil, err
+	}
+
+	responses := make([]*models.OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		if customerID != "" && order.CustomerID != customerID {
+			continue
+		}
+		responses = append(responses, models.NewOrderResponseWith(order, read))
+	}
+	return responses, nil
+}
+
+// GetCustomerSummaries aggregates the orders of a batch of customers. Customers without
+// orders are left out, like the other customers for callers limited to their own orders.
+func (s *OrderService) GetCustomerSummaries(ctx context.Context, customerIDs []string) ([]*models.CustomerSummaryResponse, error) {
+	customerID, err := authz.ReadScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if customerID != "" {
+		customerIDs = onlyCustomer(customerIDs, customerID)
+	}
+
+	summaries, err := s.repo.SummarizeCustomers(ctx, customerIDs)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to summarize customers", zap.Error(err), zap.Strings("customer_ids", customerIDs))
+		return nil, err
+	}
+
+	responses := make([]*models.CustomerSummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = models.NewCustomerSummaryResponse(summary)
+	}
+	return responses, nil
+}
+
 // SaveOrderInCache saves an order in the cache with a TTL of 60 seconds
 func (s *OrderService) SaveOrderInCache(ctx context.Context, order *domain.Order) error {
 	if order == nil {
 		return nil
 	}
 
-	cacheKey := "order:" + order.OrderID
+	cacheKey := orderCacheKey(ctx, order.OrderID)
 	orderJSON, err := json.Marshal(order)
 	if err != nil {
 		return err
@@ -152,8 +308,12 @@ func (s *OrderService) SaveOrderInCache(ctx context.Context, order *domain.Order
 
 // getFromCache attempts to retrieve an order from the cache
 func (s *OrderService) getFromCache(ctx context.Context, orderID string) (*models.OrderResponse, error) {
-	cacheKey := "order:" + orderID
-	val, err := s.cache.Get(ctx, cacheKey)
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errors.ErrMissingTenant
+	}
+
+	val, err := s.cache.Get(ctx, orderCacheKey(ctx, orderID))
 	if err != nil {
 		return nil, err
 	}
@@ -162,10 +322,61 @@ func (s *OrderService) getFromCache(ctx context.Context, orderID string) (*model
 	if err := json.Unmarshal([]byte(val), &order); err != nil {
 		return nil, err
 	}
+	if order.TenantID != tenantID {
+		return nil, errors.ErrOrderNotFound
+	}
 
 	return models.NewOrderResponse(&order), nil
 }
 
+// orderCacheKey builds the cache key of an order, prefixed with the tenant in the context
+// so tenants never share cache entries
+func orderCacheKey(ctx context.Context, orderID string) string {
+	tenantID, _ := tenant.FromContext(ctx)
+	return "tenant:" + tenantID + ":order:" + orderID
+}
+
+// withCustomer adds the customer to the fields read when the caller is limited to the orders
+// of customerID, so their ownership can be checked on sparse reads
+func withCustomer(read domain.ReadOptions, customerID string) domain.ReadOptions {
+	if customerID == "" {
+		return read
+	}
+	return withField(read, "customer_id")
+}
+
+// withVersion adds the fields the ETag of an order is computed from to the fields read
+func withVersion(read domain.ReadOptions) domain.ReadOptions {
+	return withField(withField(read, "updated_at"), "archived_at")
+}
+
+// withField adds field to the fields read, unless every field is read
+func withField(read domain.ReadOptions, field string) domain.ReadOptions {
+	if len(read.Fields) == 0 {
+		return read
+	}
+	for _, selected := range read.Fields {
+		if selected == field {
+			return read
+		}
+	}
+
+	fields := make([]string, 0, len(read.Fields)+1)
+	fields = append(fields, read.Fields...)
+	read.Fields = append(fields, field)
+	return read
+}
+
+// onlyCustomer keeps customerID among the customer IDs, if it is there
+func onlyCustomer(customerIDs []string, customerID string) []string {
+	for _, id := range customerIDs {
+		if id == customerID {
+			return []string{customerID}
+		}
+	}
+	return []string{}
+}
+
 // isValidStatusTransition checks if the status transition is valid
 func isValidStatusTransition(current, newStatus domain.OrderStatus) bool {
 	switch current {
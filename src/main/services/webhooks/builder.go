@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/repositories"
+
+	"go.uber.org/zap"
+)
+
+type WebhookService struct {
+	repo   repositories.WebhookRepository
+	cfg    config.Webhooks
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewWebhookService creates the webhook service, delivering with client. Redirects are not
+// followed, so a delivery only ever reaches the URL of its webhook, and unless private
+// networks are allowed, only public addresses are dialled.
+func NewWebhookService(repo repositories.WebhookRepository, cfg config.Webhooks, client *http.Client, logger *zap.Logger) *WebhookService {
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	if !cfg.AllowPrivateNetworks {
+		noRedirects.Transport = publicOnlyTransport(client)
+	}
+	return &WebhookService{
+		repo:   repo,
+		cfg:    cfg,
+		client: &noRedirects,
+		logger: logger,
+	}
+}
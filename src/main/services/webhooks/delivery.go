@@ -0,0 +1,180 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	domain "order-management-ms/src/main/models/datastore"
+	errors "order-management-ms/src/main/pkg/customerrors"
+
+	"go.uber.org/zap"
+)
+
+// Headers of the webhook deliveries. The signature is "sha256=<hex HMAC-SHA256>" of
+// "<timestamp>.<body>", keyed with the secret of the webhook.
+const (
+	DeliveryHeader  = "X-Webhook-ID"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// leaseMargin is added to the timeout of the deliveries to lease them, so a delivery
+	// is only claimed again if its worker stopped
+	leaseMargin = 30 * time.Second
+	// maxResponseSize is the number of bytes of the webhook responses read, so connections
+	// can be reused. Only their status line is kept in the log.
+	maxResponseSize = 1024
+)
+
+// Run delivers the due deliveries every configured interval until the context is cancelled
+func (s *WebhookService) Run(ctx context.Context) {
+	if !s.cfg.Enabled {
+		s.logger.Info("Webhook deliveries are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch means more deliveries may be due
+		for {
+			delivered, err := s.DeliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to deliver webhooks", zap.Error(err))
+			}
+			if err != nil || delivered < s.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims a batch of due deliveries and attempts them concurrently, returning the
+// number of deliveries attempted
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	deliveries, err := s.repo.ClaimDeliveries(ctx, now, now.Add(s.cfg.Timeout+leaseMargin), s.cfg.BatchSize)
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *domain.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), err
+}
+
+// deliver attempts a delivery and records the outcome: delivered, retried after a backoff, or
+// dead-lettered once its attempts are exhausted
+func (s *WebhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	logger := s.logger.With(zap.String("delivery_id", delivery.DeliveryID), zap.String("webhook_id", delivery.WebhookID))
+
+	var attempt domain.DeliveryAttempt
+	retry := true
+	webhook, err := s.repo.FindWebhook(ctx, delivery.WebhookID)
+	switch {
+	case stderrors.Is(err, errors.ErrWebhookNotFound):
+		attempt = domain.DeliveryAttempt{At: time.Now(), Error: "webhook deleted"}
+		retry = false
+	case err != nil:
+		// The lease expires and the delivery is attempted again
+		logger.Error("Failed to find webhook", zap.Error(err))
+		return
+	default:
+		attempt = s.post(ctx, webhook, delivery)
+	}
+
+	delivery.Attempts++
+	switch {
+	case attempt.Error == "" && attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &attempt.At
+	case !retry || delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = domain.DeliveryDeadLetter
+		logger.Warn("Webhook delivery dead-lettered", zap.Int("attempts", delivery.Attempts), zap.Int("status_code", attempt.StatusCode), zap.String("error", attempt.Error))
+	default:
+		delivery.NextAttemptAt = time.Now().Add(s.backoff(delivery.Attempts))
+		logger.Info("Webhook delivery failed, retrying", zap.Int("attempts", delivery.Attempts), zap.Time("next_attempt_at", delivery.NextAttemptAt), zap.Int("status_code", attempt.StatusCode), zap.String("error", attempt.Error))
+	}
+
+	if err := s.repo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		logger.Error("Failed to record webhook delivery attempt", zap.Error(err))
+	}
+}
+
+// post sends the signed payload of a delivery to its webhook
+func (s *WebhookService) post(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) domain.DeliveryAttempt {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	attempt := domain.DeliveryAttempt{At: start}
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	attempt.Response = resp.Status
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	return attempt
+}
+
+// backoff returns the delay before the next attempt of a delivery that failed attempts
+// times: the initial backoff, doubled after each failure up to the maximum backoff
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.cfg.InitialBackoff
+	for i := 1; i < attempts && delay < s.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.MaxBackoff)
+}
+
+// Sign returns the signature of a delivery body sent at timestamp, in Unix seconds. Webhooks
+// compute it with their secret and compare it to the signature header, and reject old
+// timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	modelsv2 "order-management-ms/src/main/models/api/v2"
+	domain "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/authz"
+	errors "order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/requestid"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// secretPrefix starts every generated secret, so leaked secrets are easy to recognize
+	secretPrefix = "whsec_"
+	// deliveryListLimit is the number of deliveries returned by a listing
+	deliveryListLimit = 100
+)
+
+// Service defines the interface for webhook operations
+type Service interface {
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*modelsv2.WebhookResponse, error)
+	ListWebhooks(ctx context.Context) (*modelsv2.ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListDeliveries(ctx context.Context, webhookID string, status domain.DeliveryStatus) (*modelsv2.ListWebhookDeliveriesResponse, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID string) (*modelsv2.WebhookDeliveryResponse, error)
+}
+
+// Ensure the service queues the deliveries of the published events
+var _ kafkaDto.EventPublisher = (*WebhookService)(nil)
+
+// Payload is the body POSTed to the webhooks
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// CreateWebhook creates a webhook for the tenant in the context. The returned response is the
+// only one carrying the secret.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (*modelsv2.WebhookResponse, error) {
+	if err := authz.Require(ctx, authz.ManageWebhooks); err != nil {
+		return nil, err
+	}
+	if !s.validURL(webhook.URL) {
+		return nil, errors.ErrInvalidWebhookURL
+	}
+
+	if webhook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+	webhook.WebhookID = uuid.NewString()
+	webhook.CreatedAt = time.Now()
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to create webhook", zap.Error(err), zap.String("webhook_id", webhook.WebhookID))
+		return nil, err
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Created webhook", zap.String("webhook_id", webhook.WebhookID), zap.Strings("event_types", webhook.EventTypes))
+	return modelsv2.NewWebhookResponse(webhook, true), nil
+}
+
+// ListWebhooks returns the webhooks of the tenant in the context, without their secrets
+func (s *WebhookService) ListWebhooks(ctx context.Context) (*modelsv2.ListWebhooksResponse, error) {
+	if err := authz.Require(ctx, authz.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.repo.ListWebhooks(ctx, "")
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to list webhooks", zap.Error(err))
+		return nil, err
+	}
+	return modelsv2.NewListWebhooksResponse(webhooks), nil
+}
+
+// DeleteWebhook deletes a webhook. Its pending deliveries are dead-lettered when they are
+// next attempted.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	if err := authz.Require(ctx, authz.ManageWebhooks); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteWebhook(ctx, webhookID); err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to delete webhook", zap.Error(err), zap.String("webhook_id", webhookID))
+		return fmt.Errorf("delete webhook %s: %w", webhookID, err)
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Deleted webhook", zap.String("webhook_id", webhookID))
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook, optionally only those with the
+// status
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID string, status domain.DeliveryStatus) (*modelsv2.ListWebhookDeliveriesResponse, error) {
+	if err := authz.Require(ctx, authz.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, webhookID, status, deliveryListLimit)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to list webhook deliveries", zap.Error(err), zap.String("webhook_id", webhookID))
+		return nil, err
+	}
+	return modelsv2.NewListWebhookDeliveriesResponse(deliveries), nil
+}
+
+// Redeliver queues a delivery again, whatever its status, with a new budget of attempts
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID string) (*modelsv2.WebhookDeliveryResponse, error) {
+	if err := authz.Require(ctx, authz.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.RedeliverDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		requestid.Logger(ctx, s.logger).Error("Failed to redeliver webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID))
+		return nil, fmt.Errorf("redeliver %s: %w", deliveryID, err)
+	}
+
+	requestid.Logger(ctx, s.logger).Info("Queued webhook redelivery", zap.String("webhook_id", webhookID), zap.String("delivery_id", deliveryID))
+	return modelsv2.NewWebhookDeliveryResponse(delivery), nil
+}
+
+// PublishOrderStatusChanged queues a delivery of the event to every webhook of the tenant in
+// the context subscribed to it
+func (s *WebhookService) PublishOrderStatusChanged(ctx context.Context, event kafkaDto.OrderStatusChangedEvent) error {
+	return s.enqueue(ctx, events.StatusChangedType, event)
+}
+
+func (s *WebhookService) enqueue(ctx context.Context, eventType string, data interface{}) error {
+	webhooks, err := s.repo.ListWebhooks(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("list webhooks of %s: %w", eventType, err)
+	}
+
+	now := time.Now()
+	deliveries := make([]*domain.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveryID := uuid.NewString()
+		payload, err := json.Marshal(Payload{ID: deliveryID, Type: eventType, CreatedAt: now, Data: data})
+		if err != nil {
+			return fmt.Errorf("encode %s payload: %w", eventType, err)
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			DeliveryID:    deliveryID,
+			TenantID:      webhook.TenantID,
+			WebhookID:     webhook.WebhookID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+			Log:           []domain.DeliveryAttempt{},
+			CreatedAt:     now,
+		})
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("queue %s deliveries: %w", eventType, err)
+	}
+	return nil
+}
+
+// validURL checks that a webhook URL is absolute and, unless insecure URLs are allowed, uses
+// HTTPS. Unless private networks are allowed, its host must not be a non-public address.
+func (s *WebhookService) validURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return false
+	}
+	if !s.cfg.AllowPrivateNetworks && !isPublicHost(parsed.Hostname()) {
+		return false
+	}
+	return parsed.Scheme == "https" || (parsed.Scheme == "http" && s.cfg.AllowInsecure)
+}
+
+// newSecret generates a signing secret, "whsec_<secret>"
+func newSecret() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,157 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/controllers"
+	models "order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/api"
+	"order-management-ms/src/main/services/orders"
+)
+
+// fakeOrderService creates orders without storing them
+type fakeOrderService struct {
+	orders.Service
+	created int
+}
+
+func (f *fakeOrderService) CreateOrder(ctx context.Context, order *dm.Order) (*models.OrderResponse, error) {
+	f.created++
+	order.OrderID = "ORD-1"
+	return models.NewOrderResponse(order), nil
+}
+
+func setupRouter(t *testing.T) (*gin.Engine, *fakeOrderService) {
+	return setupProtectedRouter(t, nil)
+}
+
+// setupProtectedRouter sets up the router with the authentication middleware
+func setupProtectedRouter(t *testing.T, authenticate gin.HandlerFunc) (*gin.Engine, *fakeOrderService) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeOrderService{}
+	cfg := &config.Config{Tenant: config.Tenant{Header: "X-Tenant-ID", Default: "brand-a"}}
+
+	graphqlCtrl, err := controllers.NewGraphQLController(svc, zap.NewNop())
+	require.NoError(t, err)
+	return api.SetupRouter(cfg, controllers.NewOrderController(svc, zap.NewNop()), controllers.NewOrderControllerV2(svc, zap.NewNop()), graphqlCtrl, nil, nil, nil, authenticate, nil, zap.NewNop()), svc
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	router, _ := setupRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var spec struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths["/api/v1/orders/{id}/status"], "patch")
+	assert.NotContains(t, spec.Paths["/api/v1/orders/{id}/status"], "put")
+	assert.Contains(t, spec.Paths["/api/v1/orders"], "post")
+	assert.Contains(t, spec.Paths["/api/v1/orders"], "get")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "/api/v1/openapi.json")
+}
+
+func TestRequestValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "valid body",
+			body:           `{"customer_id":"customer-123","items":[{"sku":"SKU-123","quantity":2}]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing required fields",
+			body:           `{"customer_name":"Jane"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"customer_id", "items"},
+		},
+		{
+			name:           "wrong item type",
+			body:           `{"customer_id":"customer-123","items":[{"sku":"SKU-123","quantity":"two"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"items[0].quantity"},
+		},
+		{
+			name:           "empty items",
+			body:           `{"customer_id":"customer-123","items":[]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, svc := setupRouter(t)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedFields == nil {
+				assert.Equal(t, 1, svc.created)
+				return
+			}
+
+			var resp struct {
+				Code   string `json:"code"`
+				Errors []struct {
+					Field string `json:"field"`
+				} `json:"errors"`
+			}
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "VALIDATION_ERROR", resp.Code)
+			var fields []string
+			for _, e := range resp.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.ElementsMatch(t, tt.expectedFields, fields)
+			assert.Zero(t, svc.created)
+		})
+	}
+}
+
+func TestRequestValidationFollowsAuthentication(t *testing.T) {
+	router, svc := setupProtectedRouter(t, func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	for _, path := range []string{"/api/v1/orders", "/api/v2/orders"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"customer_name":"Jane"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		assert.NotContains(t, w.Body.String(), "VALIDATION_ERROR", path)
+	}
+	assert.Zero(t, svc.created)
+}
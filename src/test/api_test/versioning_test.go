@@ -0,0 +1,222 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/docs"
+	models "order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/api"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/services/orders"
+)
+
+// versionedOrderService serves a single order and records its status changes
+type versionedOrderService struct {
+	orders.Service
+	order *dm.Order
+}
+
+func newVersionedOrderService() *versionedOrderService {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &versionedOrderService{order: &dm.Order{
+		OrderID:    "ORD-1",
+		CustomerID: "customer-123",
+		Status:     dm.StatusNew,
+		Items:      []dm.OrderItem{{Sku: "SKU-123", Quantity: 2, Price: 10.5}},
+		Total:      21,
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}}
+}
+
+func (s *versionedOrderService) CreateOrder(ctx context.Context, order *dm.Order) (*models.OrderResponse, error) {
+	order.OrderID = s.order.OrderID
+	order.Items[0].Price = 10.5
+	order.Total = 21
+	return models.NewOrderResponse(order), nil
+}
+
+func (s *versionedOrderService) GetOrder(ctx context.Context, orderID string, read dm.ReadOptions) (*models.OrderResponse, error) {
+	if orderID != s.order.OrderID {
+		return nil, customerrors.ErrOrderNotFound
+	}
+	return models.NewOrderResponseWith(s.order, read), nil
+}
+
+func (s *versionedOrderService) ListOrders(ctx context.Context, filter dm.OrderFilter, opts dm.ListOptions) (*models.ListOrdersResponse, error) {
+	total := int64(11)
+	page := &dm.OrderPage{Orders: []*dm.Order{s.order}, Total: &total, HasMore: true}
+	return models.NewListOrdersResponse(page, opts), nil
+}
+
+func (s *versionedOrderService) UpdateOrderStatus(ctx context.Context, orderID string, status dm.OrderStatus) error {
+	if orderID != s.order.OrderID {
+		return customerrors.ErrOrderNotFound
+	}
+	if s.order.Status == dm.StatusDelivered {
+		return customerrors.ErrInvalidTransition
+	}
+	s.order.Status = status
+	return nil
+}
+
+// contract serves the API and checks every successful response against the operation of
+// the specification it belongs to
+type contract struct {
+	t      *testing.T
+	router *gin.Engine
+	routes routers.Router
+	svc    *versionedOrderService
+}
+
+func newContract(t *testing.T) *contract {
+	gin.SetMode(gin.TestMode)
+	svc := newVersionedOrderService()
+	cfg := &config.Config{
+		Tenant: config.Tenant{Header: "X-Tenant-ID", Default: "brand-a"},
+		APIVersions: config.APIVersions{
+			V1DeprecatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			V1Sunset:       time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	graphqlCtrl, err := controllers.NewGraphQLController(svc, zap.NewNop())
+	require.NoError(t, err)
+	router := api.SetupRouter(cfg, controllers.NewOrderController(svc, zap.NewNop()),
+		controllers.NewOrderControllerV2(svc, zap.NewNop()), graphqlCtrl, nil, nil, nil, nil, nil, zap.NewNop())
+
+	spec, err := docs.OpenAPI()
+	require.NoError(t, err)
+	routes, err := gorillamux.NewRouter(spec)
+	require.NoError(t, err)
+
+	return &contract{t: t, router: router, routes: routes, svc: svc}
+}
+
+// do serves the request and validates the response against the specification
+func (c *contract) do(method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+
+	if w.Code < http.StatusBadRequest {
+		route, pathParams, err := c.routes.FindRoute(req)
+		require.NoError(c.t, err)
+		err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: pathParams, Route: route},
+			Status:                 w.Code,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+			Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+		})
+		assert.NoError(c.t, err, "%s %s breaks its contract: %s", method, target, w.Body.String())
+	}
+	return w
+}
+
+func TestAPIV1Contract(t *testing.T) {
+	c := newContract(t)
+
+	t.Run("orders", func(t *testing.T) {
+		w := c.do(http.MethodGet, "/api/v1/orders/ORD-1", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"items":[{"sku":"SKU-123","quantity":2,"price":10.5}],"total":21,`)
+	})
+
+	t.Run("listings", func(t *testing.T) {
+		w := c.do(http.MethodGet, "/api/v1/orders?limit=1", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `],"page":1,"limit":1,"total":11,"has_more":true,"next":"/api/v1/orders?limit=1\u0026page=2"}`)
+	})
+
+	t.Run("status updates", func(t *testing.T) {
+		w := c.do(http.MethodPatch, "/api/v1/orders/ORD-1/status", `{"status":"IN_PROGRESS"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"Order status updated successfully"}`, w.Body.String())
+	})
+
+	t.Run("deprecation headers", func(t *testing.T) {
+		for _, target := range []string{"/api/v1/orders/ORD-1", "/api/v1/orders/missing"} {
+			w := c.do(http.MethodGet, target, "")
+			assert.Equal(t, "@1790812800", w.Header().Get("Deprecation"), target)
+			assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"), target)
+			assert.Equal(t, `</api/v2/orders>; rel="successor-version"`, w.Header().Get("Link"), target)
+		}
+	})
+
+	t.Run("no cancel endpoint", func(t *testing.T) {
+		w := c.do(http.MethodPost, "/api/v1/orders/ORD-1/cancel", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAPIV2Contract(t *testing.T) {
+	c := newContract(t)
+
+	t.Run("amounts are strings", func(t *testing.T) {
+		w := c.do(http.MethodPost, "/api/v2/orders", `{"customer_id":"customer-123","items":[{"sku":"SKU-123","quantity":2}]}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"items":[{"sku":"SKU-123","quantity":2,"price":"10.50"}],"total":"21.00"`)
+
+		w = c.do(http.MethodGet, "/api/v2/orders/ORD-1?fields=order_id,total", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"order_id":"ORD-1","total":"21.00"}`, w.Body.String())
+	})
+
+	t.Run("listings are wrapped in an envelope", func(t *testing.T) {
+		w := c.do(http.MethodGet, "/api/v2/orders?limit=1&fields=order_id", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[{"order_id":"ORD-1"}],"meta":{"page":1,"limit":1,"total":11,"has_more":true},`+
+			`"links":{"next":"/api/v2/orders?fields=order_id&limit=1&page=2"}}`, w.Body.String())
+	})
+
+	t.Run("status updates return the order", func(t *testing.T) {
+		w := c.do(http.MethodPatch, "/api/v2/orders/ORD-1/status", `{"status":"in_progress"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"IN_PROGRESS"`)
+	})
+
+	t.Run("orders can be cancelled", func(t *testing.T) {
+		w := c.do(http.MethodPost, "/api/v2/orders/ORD-1/cancel", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"CANCELLED"`)
+
+		w = c.do(http.MethodPost, "/api/v2/orders/missing/cancel", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"ORDER_NOT_FOUND"`)
+	})
+
+	t.Run("delivered orders cannot be cancelled", func(t *testing.T) {
+		c.svc.order.Status = dm.StatusDelivered
+		w := c.do(http.MethodPost, "/api/v2/orders/ORD-1/cancel", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"INVALID_TRANSITION"`)
+	})
+
+	t.Run("no deprecation headers", func(t *testing.T) {
+		w := c.do(http.MethodGet, "/api/v2/orders/ORD-1", "")
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Empty(t, w.Header().Get("Sunset"))
+	})
+}
@@ -0,0 +1,137 @@
+package apikey_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// stubAuthenticator accepts a single key from any address
+type stubAuthenticator struct {
+	clientIP string
+}
+
+func (s *stubAuthenticator) Authenticate(ctx context.Context, plainKey, clientIP string) (*dm.APIKey, error) {
+	s.clientIP = clientIP
+	if plainKey != "omk_1234_secret" {
+		return nil, customerrors.ErrInvalidAPIKey
+	}
+	return &dm.APIKey{KeyID: "1234", TenantID: "brand-a", Scopes: []string{"orders:read", "orders:write"}}, nil
+}
+
+// rejectBearer stands for the bearer token authentication of the other callers
+func rejectBearer(c *gin.Context) {
+	_ = c.Error(customerrors.ErrMissingAuthToken)
+	c.Abort()
+}
+
+// setupRouter serves a route returning the tenant, the key and the access of the caller
+func setupRouter(authenticator apikey.Authenticator, next gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	_ = r.SetTrustedProxies(nil)
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/orders",
+		apikey.Middleware("X-API-Key", authenticator, next, zap.NewNop()),
+		tenant.Middleware("X-Tenant-ID", "brand-default"),
+		func(c *gin.Context) {
+			ctx := c.Request.Context()
+			tenantID, _ := tenant.FromContext(ctx)
+			keyID, _ := apikey.FromContext(ctx)
+			access, ok := authz.FromContext(ctx)
+			c.JSON(http.StatusOK, gin.H{
+				"tenant":    tenantID,
+				"key_id":    keyID,
+				"can_read":  ok && access.Can(authz.ReadOrders),
+				"can_write": ok && access.Can(authz.WriteOrders),
+				"can_ship":  ok && access.Can(authz.UpdateStatus),
+			})
+		})
+	return r
+}
+
+func get(r *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	authenticator := &stubAuthenticator{}
+	r := setupRouter(authenticator, rejectBearer)
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid key binds the tenant and the scopes",
+			headers:        map[string]string{"X-API-Key": "omk_1234_secret"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a","key_id":"1234","can_read":true,"can_write":true,"can_ship":false}`,
+		},
+		{
+			name:           "valid key with its own tenant",
+			headers:        map[string]string{"X-API-Key": "omk_1234_secret", "X-Tenant-ID": "brand-a"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a","key_id":"1234","can_read":true,"can_write":true,"can_ship":false}`,
+		},
+		{
+			name:           "valid key with another tenant",
+			headers:        map[string]string{"X-API-Key": "omk_1234_secret", "X-Tenant-ID": "brand-b"},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"code":"FORBIDDEN","message":"insufficient permissions"}`,
+		},
+		{
+			name:           "invalid key",
+			headers:        map[string]string{"X-API-Key": "omk_1234_other"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_API_KEY","message":"invalid or revoked API key"}`,
+		},
+		{
+			name:           "no key falls back to the bearer token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"MISSING_AUTH_TOKEN","message":"missing authorization token"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(r, tt.headers)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+
+	// Without trusted proxies the forwarded address is ignored, so allow-lists cannot be spoofed
+	get(r, map[string]string{"X-API-Key": "omk_1234_secret", "X-Forwarded-For": "203.0.113.10"})
+	assert.Equal(t, "192.0.2.1", authenticator.clientIP)
+}
+
+func TestMiddlewareWithoutOtherAuthentication(t *testing.T) {
+	r := setupRouter(&stubAuthenticator{}, nil)
+
+	w := get(r, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "MISSING_API_KEY")
+}
@@ -0,0 +1,351 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/pkg/auth"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+const (
+	issuer   = "https://auth.example.com/"
+	audience = "order-management-ms"
+)
+
+// keys are the signing keys generated for a test and their key set document
+type keys struct {
+	rsa    *rsa.PrivateKey
+	secret []byte
+	jwks   []byte
+}
+
+func generateKeys(t *testing.T) *keys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "oct", "kid": "hmac-1", "alg": "HS256", "k": encode(secret)},
+	}})
+	require.NoError(t, err)
+
+	return &keys{rsa: rsaKey, secret: secret, jwks: jwks}
+}
+
+// file writes the key set to a temporary file and returns its path
+func (k *keys) file(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, k.jwks, 0o600))
+	return path
+}
+
+// claims returns valid claims for the subject of brand-a, expiring in an hour
+func claims(subject string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":       subject,
+		"iss":       issuer,
+		"aud":       audience,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"roles":     []string{"customer"},
+		"tenant_id": "brand-a",
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, c jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newVerifier(t *testing.T, source string) *auth.Verifier {
+	return newVerifierWith(t, source, config.Auth{}, "")
+}
+
+func newVerifierWith(t *testing.T, source string, cfg config.Auth, defaultTenant string) *auth.Verifier {
+	keySet, err := auth.NewKeySet(context.Background(), source, time.Hour)
+	require.NoError(t, err)
+	cfg.Issuer, cfg.Audience, cfg.Leeway = issuer, audience, time.Second
+	return auth.NewVerifier(keySet, cfg, defaultTenant)
+}
+
+// setupRouter serves a route returning the claims and the tenant found in the request context
+func setupRouter(verifier *auth.Verifier) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/me", auth.Middleware(verifier, authz.DefaultPolicy(), zap.NewNop()), func(c *gin.Context) {
+		claims, ok := auth.FromContext(c.Request.Context())
+		tenantID, bound := tenant.FromContext(c.Request.Context())
+		if !ok || !bound {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"subject": claims.Subject, "roles": claims.Roles, "tenant": tenantID})
+	})
+	return r
+}
+
+func get(r *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	k := generateKeys(t)
+	r := setupRouter(newVerifier(t, k.file(t)))
+
+	expired := claims("customer-123")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	otherIssuer := claims("customer-123")
+	otherIssuer["iss"] = "https://evil.example.com/"
+	otherAudience := claims("customer-123")
+	otherAudience["aud"] = "another-service"
+	noExpiry := claims("customer-123")
+	delete(noExpiry, "exp")
+	noSubject := claims("")
+	noTenant := claims("customer-123")
+	delete(noTenant, "tenant_id")
+	invalidTenant := claims("customer-123")
+	invalidTenant["tenant_id"] = "brand a/../b"
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "RS256 token",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, claims("customer-123")),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"subject":"customer-123","roles":["customer"],"tenant":"brand-a"}`,
+		},
+		{
+			name:           "HS256 token",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodHS256, "hmac-1", k.secret, claims("partner-1")),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"subject":"partner-1","roles":["customer"],"tenant":"brand-a"}`,
+		},
+		{
+			name:           "token without key ID",
+			authorization:  "bearer " + sign(t, jwt.SigningMethodRS256, "", k.rsa, claims("customer-123")),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"subject":"customer-123","roles":["customer"],"tenant":"brand-a"}`,
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"MISSING_AUTH_TOKEN","message":"missing authorization token"}`,
+		},
+		{
+			name:           "other scheme",
+			authorization:  "Basic dXNlcjpwYXNz",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"MISSING_AUTH_TOKEN","message":"missing authorization token"}`,
+		},
+		{
+			name:           "malformed token",
+			authorization:  "Bearer not-a-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "expired token",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, expired),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "token without expiry",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, noExpiry),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "token without subject",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, noSubject),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "token without tenant",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, noTenant),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "token with an invalid tenant",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, invalidTenant),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "other issuer",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, otherIssuer),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "other audience",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, otherAudience),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "unknown signing key",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-1", otherKey, claims("customer-123")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "unknown key ID",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodRS256, "rsa-2", k.rsa, claims("customer-123")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name: "HS256 token signed with the RSA public key",
+			authorization: "Bearer " + sign(t, jwt.SigningMethodHS256, "rsa-1",
+				k.rsa.PublicKey.N.Bytes(), claims("customer-123")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+		{
+			name:           "unsigned token",
+			authorization:  "Bearer " + sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims("customer-123")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":"INVALID_AUTH_TOKEN","message":"invalid or expired authorization token"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(r, tt.authorization)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestTenantClaim(t *testing.T) {
+	k := generateKeys(t)
+
+	// The tenant is read from the configured claim
+	r := setupRouter(newVerifierWith(t, k.file(t), config.Auth{TenantClaim: "https://example.com/tenant"}, ""))
+	custom := claims("customer-123")
+	custom["https://example.com/tenant"] = "brand-b"
+	w := get(r, "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, custom))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject":"customer-123","roles":["customer"],"tenant":"brand-b"}`, w.Body.String())
+
+	// Tokens without the claim are bound to the default tenant, when there is one
+	r = setupRouter(newVerifierWith(t, k.file(t), config.Auth{}, "brand-default"))
+	noTenant := claims("customer-123")
+	delete(noTenant, "tenant_id")
+	w = get(r, "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, noTenant))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject":"customer-123","roles":["customer"],"tenant":"brand-default"}`, w.Body.String())
+
+	w = get(r, "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, claims("customer-123")))
+	assert.JSONEq(t, `{"subject":"customer-123","roles":["customer"],"tenant":"brand-a"}`, w.Body.String())
+}
+
+func TestKeySetFromURL(t *testing.T) {
+	k := generateKeys(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(k.jwks)
+	}))
+	defer server.Close()
+
+	r := setupRouter(newVerifier(t, server.URL))
+	w := get(r, "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, claims("customer-123")))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestKeySetOutage(t *testing.T) {
+	k := generateKeys(t)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			time.Sleep(time.Second)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(k.jwks)
+	}))
+	defer server.Close()
+	verifier := newVerifier(t, server.URL)
+
+	// Tokens with unknown keys neither wait for the unavailable key set nor each fetch it
+	unknownKey := sign(t, jwt.SigningMethodRS256, "rsa-2", k.rsa, claims("customer-123"))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := verifier.Verify(context.Background(), unknownKey)
+			assert.Error(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// The keys already loaded keep verifying
+	_, err := verifier.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", k.rsa, claims("customer-123")))
+	assert.NoError(t, err)
+}
+
+func TestKeySetErrors(t *testing.T) {
+	_, err := auth.NewKeySet(context.Background(), filepath.Join(t.TempDir(), "missing.json"), time.Hour)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"EC","kid":"ec-1"}]}`), 0o600))
+	_, err = auth.NewKeySet(context.Background(), path, time.Hour)
+	assert.Error(t, err)
+}
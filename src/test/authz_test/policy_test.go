@@ -0,0 +1,55 @@
+package authz_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-management-ms/src/main/pkg/authz"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	t.Run("maps roles to permissions", func(t *testing.T) {
+		policy, err := authz.LoadPolicy(writePolicy(t, `{"support": ["orders:read"], "courier": ["orders:status", "orders:deliver"]}`))
+		require.NoError(t, err)
+
+		access := policy.Access("staff-1", []string{"support", "courier", "unknown"})
+		assert.True(t, access.Can(authz.ReadOrders))
+		assert.True(t, access.Can(authz.DeliverOrders))
+		assert.False(t, access.Can(authz.WriteOrders))
+	})
+
+	t.Run("rejects unknown permissions", func(t *testing.T) {
+		_, err := authz.LoadPolicy(writePolicy(t, `{"support": ["orders:delete"]}`))
+		assert.ErrorContains(t, err, `unknown permission "orders:delete"`)
+	})
+
+	t.Run("rejects invalid files", func(t *testing.T) {
+		_, err := authz.LoadPolicy(writePolicy(t, `["orders:read"]`))
+		assert.Error(t, err)
+
+		_, err = authz.LoadPolicy(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := authz.DefaultPolicy()
+	require.NoError(t, policy.Validate())
+
+	customer := policy.Access("customer-1", []string{"customer"})
+	assert.True(t, customer.Can(authz.ReadOwnOrders))
+	assert.False(t, customer.Can(authz.ReadOrders))
+
+	assert.False(t, policy.Access("staff-1", []string{"operator"}).Can(authz.DeliverOrders))
+	assert.True(t, policy.Access("staff-2", []string{"fulfillment"}).Can(authz.DeliverOrders))
+}
@@ -0,0 +1,40 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-management-ms/src/main/config"
+)
+
+func TestLoadConfigRejectsInvalidSettings(t *testing.T) {
+	_, err := config.LoadConfig()
+	require.NoError(t, err, "the defaults are valid")
+
+	tests := []struct {
+		env   string
+		value string
+	}{
+		{"ARCHIVE_INTERVAL", "0s"},
+		{"ARCHIVE_INTERVAL", "-1m"},
+		{"ARCHIVE_RETENTION", "0s"},
+		{"ARCHIVE_RETENTION", "-24h"},
+		{"ARCHIVE_BATCH_SIZE", "0"},
+		{"ARCHIVE_BATCH_SIZE", "-1"},
+		{"EVENTS_HEARTBEAT", "0s"},
+		{"EVENTS_REPLAY_LIMIT", "0"},
+		{"WEBHOOK_POLL_INTERVAL", "0s"},
+		{"GRPC_WATCH_INTERVAL", "0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+
+			_, err := config.LoadConfig()
+			assert.ErrorContains(t, err, tt.env+" must be positive")
+		})
+	}
+}
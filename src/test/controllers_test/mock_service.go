@@ -24,8 +24,8 @@ func (m *mockOrderService) CreateOrder(ctx context.Context, order *dm.Order) (*a
 }
 
 // GetOrder mocks the GetOrder method
-func (m *mockOrderService) GetOrder(ctx context.Context, orderID string) (*api.OrderResponse, error) {
-	args := m.Called(ctx, orderID)
+func (m *mockOrderService) GetOrder(ctx context.Context, orderID string, read dm.ReadOptions) (*api.OrderResponse, error) {
+	args := m.Called(ctx, orderID, read)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -33,12 +33,25 @@ func (m *mockOrderService) GetOrder(ctx context.Context, orderID string) (*api.O
 }
 
 // ListOrders mocks the ListOrders method
-func (m *mockOrderService) ListOrders(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*api.OrderResponse, error) {
-	args := m.Called(ctx, filters, page, limit)
+func (m *mockOrderService) ListOrders(ctx context.Context, filter dm.OrderFilter, opts dm.ListOptions) (*api.ListOrdersResponse, error) {
+	args := m.Called(ctx, filter, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*api.OrderResponse), args.Error(1)
+	return args.Get(0).(*api.ListOrdersResponse), args.Error(1)
+}
+
+// ExportOrders mocks the ExportOrders method, writing the orders returned by the mock
+func (m *mockOrderService) ExportOrders(ctx context.Context, filter dm.OrderFilter, sort []dm.SortField, read dm.ReadOptions, write func(*api.OrderResponse) error) error {
+	args := m.Called(ctx, filter, sort, read)
+	if orders, ok := args.Get(0).([]*api.OrderResponse); ok {
+		for _, order := range orders {
+			if err := write(order); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
 }
 
 // UpdateOrderStatus mocks the UpdateOrderStatus method
@@ -46,3 +59,30 @@ func (m *mockOrderService) UpdateOrderStatus(ctx context.Context, orderID string
 	args := m.Called(ctx, orderID, status)
 	return args.Error(0)
 }
+
+// SearchOrders mocks the SearchOrders method
+func (m *mockOrderService) SearchOrders(ctx context.Context, query string, limit int) (*api.SearchOrdersResponse, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*api.SearchOrdersResponse), args.Error(1)
+}
+
+// GetOrdersByIDs mocks the GetOrdersByIDs method
+func (m *mockOrderService) GetOrdersByIDs(ctx context.Context, orderIDs []string, read dm.ReadOptions) ([]*api.OrderResponse, error) {
+	args := m.Called(ctx, orderIDs, read)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*api.OrderResponse), args.Error(1)
+}
+
+// GetCustomerSummaries mocks the GetCustomerSummaries method
+func (m *mockOrderService) GetCustomerSummaries(ctx context.Context, customerIDs []string) ([]*api.CustomerSummaryResponse, error) {
+	args := m.Called(ctx, customerIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*api.CustomerSummaryResponse), args.Error(1)
+}
@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/models/api"
+)
+
+func TestGetOrderConditional(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 1, 12, 30, 15, 250*int(time.Millisecond), time.UTC)
+	order := &api.OrderResponse{OrderID: "ORD-1", CustomerID: "customer-123", Status: "NEW", UpdatedAt: updatedAt}
+	etag := order.ETag()
+
+	mockService := new(mockOrderService)
+	mockService.On("GetOrder", mock.Anything, "ORD-1", mock.Anything).Return(order, nil)
+	router := setupTestRouter(controllers.NewOrderController(mockService, zap.NewNop()))
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "unconditional", expectedStatus: http.StatusOK},
+		{name: "matching ETag", headers: map[string]string{"If-None-Match": etag}, expectedStatus: http.StatusNotModified},
+		{name: "matching ETag in a list", headers: map[string]string{"If-None-Match": `W/"other", ` + etag}, expectedStatus: http.StatusNotModified},
+		{name: "any ETag", headers: map[string]string{"If-None-Match": "*"}, expectedStatus: http.StatusNotModified},
+		{name: "stale ETag", headers: map[string]string{"If-None-Match": `W/"other"`}, expectedStatus: http.StatusOK},
+		{name: "not modified since", headers: map[string]string{"If-Modified-Since": updatedAt.Format(http.TimeFormat)}, expectedStatus: http.StatusNotModified},
+		{name: "modified since", headers: map[string]string{"If-Modified-Since": updatedAt.Add(-time.Second).Format(http.TimeFormat)}, expectedStatus: http.StatusOK},
+		{
+			name:           "stale ETag takes precedence over the date",
+			headers:        map[string]string{"If-None-Match": `W/"other"`, "If-Modified-Since": updatedAt.Format(http.TimeFormat)},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/ORD-1", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "Thu, 01 Oct 2026 12:30:15 GMT", w.Header().Get("Last-Modified"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOrderETagChangesWithVersion(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 1, 12, 30, 15, 0, time.UTC)
+	order := &api.OrderResponse{OrderID: "ORD-1", UpdatedAt: updatedAt}
+	etag := order.ETag()
+	assert.Regexp(t, `^W/"[0-9a-z-]+"$`, etag)
+
+	order.UpdatedAt = updatedAt.Add(time.Millisecond)
+	assert.NotEqual(t, etag, order.ETag())
+
+	archived := order.ETag()
+	order.ArchivedAt = &updatedAt
+	assert.NotEqual(t, archived, order.ETag())
+}
@@ -4,23 +4,28 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 
 	"order-management-ms/src/main/controllers"
 	"order-management-ms/src/main/models/api"
 	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
 )
 
 // Helper function to create a test router with the controller
 func setupTestRouter(ctrl *controllers.OrderController) *gin.Engine {
 	r := gin.Default()
+	r.Use(customerrors.ErrorHandler())
 	api := r.Group("/api/v1")
 	{
 		api.POST("/orders", ctrl.CreateOrder)
@@ -61,7 +66,7 @@ func TestCreateOrder(t *testing.T) {
 					Return(createTestOrderResponse(), nil)
 			},
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}],"created_at":"%s","updated_at":"%s"}`,
+			expectedBody:   `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}],"total":21.98,"created_at":"%s","updated_at":"%s"}`,
 		},
 		{
 			name:           "invalid request body",
@@ -111,14 +116,14 @@ func TestCreateOrder(t *testing.T) {
 				// Only check timestamps if they exist in the response
 				if hasCreatedAt && hasUpdatedAt {
 					// Format the expected body with actual timestamps
-					expectedBody := `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}],"created_at":"` +
+					expectedBody := `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}],"total":21.98,"created_at":"` +
 						createdAt + `","updated_at":"` + updatedAt + `"}`
 
 					// Compare the JSON objects, ignoring the order of fields
 					assert.JSONEq(t, expectedBody, w.Body.String())
 				} else {
 					// If timestamps are not present, just check the basic structure
-					expectedBasic := `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}]}`
+					expectedBasic := `{"order_id":"order-123","customer_id":"customer-123","status":"NEW","items":[{"sku":"SKU-123","quantity":2,"price":10.99}],"total":21.98}`
 					var expectedMap, actualMap map[string]interface{}
 					json.Unmarshal([]byte(expectedBasic), &expectedMap)
 					json.Unmarshal(w.Body.Bytes(), &actualMap)
@@ -150,7 +155,7 @@ func TestGetOrder(t *testing.T) {
 			name:    "successful order retrieval",
 			orderID: "order-123",
 			setupMock: func(mockSvc *mockOrderService) {
-				mockSvc.On("GetOrder", mock.Anything, "order-123").
+				mockSvc.On("GetOrder", mock.Anything, "order-123", dm.ReadOptions{}).
 					Return(createTestOrderResponse(), nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -160,11 +165,21 @@ func TestGetOrder(t *testing.T) {
 			name:    "order not found",
 			orderID: "nonexistent",
 			setupMock: func(mockSvc *mockOrderService) {
-				mockSvc.On("GetOrder", mock.Anything, "nonexistent").
-					Return(nil, errors.New("order not found"))
+				mockSvc.On("GetOrder", mock.Anything, "nonexistent", dm.ReadOptions{}).
+					Return(nil, fmt.Errorf("find order nonexistent: %w", customerrors.ErrOrderNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"code":"ORDER_NOT_FOUND","message":"order not found"}`,
+		},
+		{
+			name:    "repository failure",
+			orderID: "order-123",
+			setupMock: func(mockSvc *mockOrderService) {
+				mockSvc.On("GetOrder", mock.Anything, "order-123", dm.ReadOptions{}).
+					Return(nil, errors.New("connection refused"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"order not found"}`,
+			expectedBody:   `{"code":"INTERNAL_SERVER_ERROR","message":"internal server error"}`,
 		},
 	}
 
@@ -186,6 +201,7 @@ func TestGetOrder(t *testing.T) {
 
 			// Make request
 			req, _ := http.NewRequest(http.MethodGet, url, nil)
+			req.Header.Set("Accept", "application/json")
 
 			// Execute request
 			w := httptest.NewRecorder()
@@ -237,6 +253,7 @@ func createTestOrder() *dm.Order {
 				Price:    10.99,
 			},
 		},
+		Total: 21.98,
 	}
 }
 
@@ -257,5 +274,378 @@ func createTestOrderResponse() *api.OrderResponse {
 		CustomerID: order.CustomerID,
 		Status:     string(order.Status),
 		Items:      items,
+		Total:      order.Total,
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	cursor := &dm.Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: primitive.NewObjectID()}
+
+	// Test cases
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "page pagination returns an envelope with links",
+			query: "?page=2&limit=5&status=NEW",
+			setupMock: func(mockSvc *mockOrderService) {
+				total := int64(12)
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusNew}}, dm.ListOptions{Page: 2, Limit: 5}).
+					Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Page: 2, Limit: 5, Total: &total, HasMore: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":[],"page":2,"limit":5,"total":12,"has_more":true,` +
+				`"next":"/api/v1/orders?limit=5&page=3&status=NEW","prev":"/api/v1/orders?limit=5&page=1&status=NEW"}`,
+		},
+		{
+			name:  "total can be skipped",
+			query: "?include_total=false",
+			setupMock: func(mockSvc *mockOrderService) {
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 10, SkipTotal: true}).
+					Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Page: 1, Limit: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[],"page":1,"limit":10,"has_more":false}`,
+		},
+		{
+			name:  "empty cursor returns the first page with its cursors",
+			query: "?cursor=&limit=5",
+			setupMock: func(mockSvc *mockOrderService) {
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 5, CursorMode: true}).
+					Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Limit: 5, HasMore: true, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[],"limit":5,"has_more":true,"next_cursor":"next","next":"/api/v1/orders?cursor=next&limit=5"}`,
+		},
+		{
+			name:  "cursor is decoded and passed to the service",
+			query: "?status=NEW&cursor=" + cursor.Encode(),
+			setupMock: func(mockSvc *mockOrderService) {
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusNew}}, mock.MatchedBy(func(opts dm.ListOptions) bool {
+					return opts.CursorMode && opts.Cursor.ID == cursor.ID && opts.Cursor.CreatedAt.Equal(cursor.CreatedAt)
+				})).Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Limit: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[],"limit":10,"has_more":false}`,
+		},
+		{
+			name:  "filters are parsed into a typed filter",
+			query: "?status=NEW,in_progress&customer_id=customer-123&sku=SKU-123&created_from=2025-01-01&created_to=2025-01-31&min_total=10&max_total=99.5",
+			setupMock: func(mockSvc *mockOrderService) {
+				createdFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				createdTo := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+				minTotal, maxTotal := 10.0, 99.5
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{
+					Statuses:    []dm.OrderStatus{dm.StatusNew, dm.StatusInProgress},
+					CustomerID:  "customer-123",
+					SKU:         "SKU-123",
+					CreatedFrom: &createdFrom,
+					CreatedTo:   &createdTo,
+					MinTotal:    &minTotal,
+					MaxTotal:    &maxTotal,
+				}, dm.ListOptions{Page: 1, Limit: 10}).Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Page: 1, Limit: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[],"page":1,"limit":10,"has_more":false}`,
+		},
+		{
+			name:           "invalid status filter",
+			query:          "?status=NEW,SHIPPED",
+			setupMock:      func(mockSvc *mockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_STATUS","message":"invalid status"}`,
+		},
+		{
+			name:           "inverted total range",
+			query:          "?min_total=50&max_total=10",
+			setupMock:      func(mockSvc *mockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_TOTAL_FILTER","message":"invalid order total filter"}`,
+		},
+		{
+			name:  "sort keys are parsed in order",
+			query: "?sort=status,-created_at",
+			setupMock: func(mockSvc *mockOrderService) {
+				mockSvc.On("ListOrders", mock.Anything, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 10, Sort: []dm.SortField{
+					{Field: dm.SortByStatus},
+					{Field: dm.SortByCreatedAt, Descending: true},
+				}}).Return(&api.ListOrdersResponse{Data: []*api.OrderResponse{}, Page: 1, Limit: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[],"page":1,"limit":10,"has_more":false}`,
+		},
+		{
+			name:           "sort on a field outside the allow-list",
+			query:          "?sort=items.sku",
+			setupMock:      func(mockSvc *mockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_SORT","message":"invalid sort, allowed fields are created_at, updated_at, status, total and customer_id"}`,
+		},
+		{
+			name:           "cursor pagination with a custom sort",
+			query:          "?cursor=&sort=total",
+			setupMock:      func(mockSvc *mockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"CURSOR_SORT","message":"cursor pagination only supports the default sort"}`,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=not-a-cursor",
+			setupMock:      func(mockSvc *mockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_CURSOR","message":"invalid cursor"}`,
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock
+			mockSvc := &mockOrderService{}
+			tt.setupMock(mockSvc)
+
+			// Create controller with mock service
+			ctrl := controllers.NewOrderController(mockSvc, zap.NewNop())
+
+			// Setup test router
+			r := setupTestRouter(ctrl)
+
+			// Make request
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders"+tt.query, nil)
+			req.Header.Set("Accept", "application/json")
+
+			// Execute request
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Assertions
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			// Verify mock expectations
+			mockSvc.AssertExpectations(t)
+		})
 	}
 }
+
+func TestGetOrderSparseFieldset(t *testing.T) {
+	changedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	read := dm.ReadOptions{Fields: []string{"order_id", "status"}, Expand: []string{dm.ExpandHistory}}
+
+	order := createTestOrder()
+	order.StatusHistory = []dm.StatusChange{{Status: dm.StatusNew, ChangedAt: changedAt}}
+
+	mockSvc := &mockOrderService{}
+	mockSvc.On("GetOrder", mock.Anything, "order-123", read).
+		Return(api.NewOrderResponseWith(order, read), nil)
+
+	r := setupTestRouter(controllers.NewOrderController(mockSvc, zap.NewNop()))
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/order-123?fields=order_id,status&expand=history", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"order_id":"order-123","status":"NEW","history":[{"status":"NEW","changed_at":"2025-01-02T03:04:05Z"}]}`, w.Body.String())
+	mockSvc.AssertExpectations(t)
+
+	// Unknown fields are rejected before reaching the service
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders/order-123?fields=order_id,secret", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateOrderValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedErrors string
+	}{
+		{
+			name:           "missing fields",
+			body:           `{}`,
+			expectedErrors: `[{"field":"customer_id","message":"is required"},{"field":"items","message":"is required"}]`,
+		},
+		{
+			name:           "empty items",
+			body:           `{"customer_id":"customer-123","items":[]}`,
+			expectedErrors: `[{"field":"items","message":"must have at least 1 elements"}]`,
+		},
+		{
+			name: "invalid items",
+			body: `{"customer_id":"customer-123","items":[{"sku":"SKU-123","quantity":0},{"sku":"SKU 123!","quantity":1}]}`,
+			expectedErrors: `[{"field":"items[0].quantity","message":"must be greater than 0"},` +
+				`{"field":"items[1].sku","message":"must be a valid SKU: letters, digits, '.', '_' and '-', up to 64 characters"}]`,
+		},
+		{
+			name:           "negative price",
+			body:           `{"customer_id":"customer-123","items":[{"sku":"SKU-123","quantity":1,"price":-10.99}]}`,
+			expectedErrors: `[{"field":"items[0].price","message":"must be greater than or equal to 0"}]`,
+		},
+		{
+			name:           "null body",
+			body:           `null`,
+			expectedErrors: `[{"field":"customer_id","message":"is required"},{"field":"items","message":"is required"}]`,
+		},
+		{
+			name:           "invalid customer ID",
+			body:           `{"customer_id":"customer 123","items":[{"sku":"SKU-123","quantity":1}]}`,
+			expectedErrors: `[{"field":"customer_id","message":"must be a valid customer ID: letters, digits, '_' and '-', up to 64 characters"}]`,
+		},
+		{
+			name:           "wrong type",
+			body:           `{"customer_id":123,"items":[{"sku":"SKU-123","quantity":1}]}`,
+			expectedErrors: `[{"field":"customer_id","message":"must be of type string"}]`,
+		},
+		{
+			name: "malformed JSON",
+			body: `{"customer_id":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockOrderService{}
+			r := setupTestRouter(controllers.NewOrderController(mockSvc, zap.NewNop()))
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var resp struct {
+				Code   string          `json:"code"`
+				Errors json.RawMessage `json:"errors"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "VALIDATION_ERROR", resp.Code)
+			if tt.expectedErrors != "" {
+				assert.JSONEq(t, tt.expectedErrors, string(resp.Errors))
+			}
+			mockSvc.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateOrderStatusErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "wrapped order not found",
+			serviceErr:     fmt.Errorf("find order order-123: %w", customerrors.ErrOrderNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"code":"ORDER_NOT_FOUND","message":"order not found"}`,
+		},
+		{
+			name:           "invalid transition",
+			serviceErr:     customerrors.ErrInvalidTransition,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_TRANSITION","message":"invalid status transition"}`,
+		},
+		{
+			name:           "repository failure",
+			serviceErr:     errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":"FAILED_TO_UPDATE_ORDER","message":"failed to update order"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockOrderService{}
+			mockSvc.On("UpdateOrderStatus", mock.Anything, "order-123", dm.StatusDelivered).Return(tt.serviceErr)
+
+			r := setupTestRouter(controllers.NewOrderController(mockSvc, zap.NewNop()))
+
+			req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/order-123/state", bytes.NewBufferString(`{"status":"DELIVERED"}`))
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		expectedType   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "problem document by default",
+			expectedType:   customerrors.MIMEProblemJSON,
+			expectedStatus: http.StatusNotFound,
+			expectedBody: `{"type":"https://order-management-ms/problems/order-not-found","title":"Not Found","status":404,` +
+				`"detail":"order not found","instance":"/api/v1/orders/nonexistent?fields=order_id","code":"ORDER_NOT_FOUND"}`,
+		},
+		{
+			name:           "problem document requested",
+			accept:         "application/problem+json",
+			expectedType:   customerrors.MIMEProblemJSON,
+			expectedStatus: http.StatusNotFound,
+			expectedBody: `{"type":"https://order-management-ms/problems/order-not-found","title":"Not Found","status":404,` +
+				`"detail":"order not found","instance":"/api/v1/orders/nonexistent?fields=order_id","code":"ORDER_NOT_FOUND"}`,
+		},
+		{
+			name:           "legacy client",
+			accept:         "application/json, text/plain, */*",
+			expectedType:   "application/json",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"code":"ORDER_NOT_FOUND","message":"order not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockOrderService{}
+			mockSvc.On("GetOrder", mock.Anything, "nonexistent", mock.Anything).
+				Return(nil, fmt.Errorf("find order nonexistent: %w", customerrors.ErrOrderNotFound))
+
+			r := setupTestRouter(controllers.NewOrderController(mockSvc, zap.NewNop()))
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/nonexistent?fields=order_id", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedType)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+
+	t.Run("validation failures are listed in errors", func(t *testing.T) {
+		r := setupTestRouter(controllers.NewOrderController(&mockOrderService{}, zap.NewNop()))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"customer_id":"customer-123","items":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type":"https://order-management-ms/problems/validation-error","title":"Bad Request","status":400,`+
+			`"detail":"request validation failed","instance":"/api/v1/orders","code":"VALIDATION_ERROR",`+
+			`"errors":[{"field":"items","message":"must have at least 1 elements"}]}`, w.Body.String())
+	})
+}
@@ -0,0 +1,234 @@
+package controllers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// eventsFixture serves the event routes over a stream running on miniredis
+type eventsFixture struct {
+	server  *httptest.Server
+	stream  *events.Stream
+	service *mockOrderService
+}
+
+func newEventsFixture(t *testing.T) *eventsFixture {
+	return newEventsFixtureWithReplayLimit(t, 100)
+}
+
+// newEventsFixtureWithReplayLimit serves the event routes, replaying at most replayLimit
+// missed events at a time
+func newEventsFixtureWithReplayLimit(t *testing.T, replayLimit int64) *eventsFixture {
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cfg := config.Events{Stream: "order_events", StreamMaxLen: 100, Buffer: 16, ReplayLimit: replayLimit, Heartbeat: time.Minute}
+	stream := events.NewStream(client, cfg, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go stream.Run(ctx)
+
+	// Wait until the stream reads the events appended to it
+	warmup := stream.Subscribe(events.Filter{TenantID: "warmup"})
+	require.Eventually(t, func() bool {
+		_ = stream.PublishOrderStatusChanged(tenant.NewContext(context.Background(), "warmup"), kafkaDto.OrderStatusChangedEvent{})
+		select {
+		case <-warmup.Events():
+			return true
+		case <-time.After(20 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, time.Millisecond)
+	stream.Unsubscribe(warmup)
+
+	service := new(mockOrderService)
+	ctrl := controllers.NewOrderEventsController(service, stream, cfg, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(customerrors.ErrorHandler())
+	orders := r.Group("/api/v1/orders", tenant.Middleware("X-Tenant-ID", "brand-a"))
+	orders.GET("/events", ctrl.StreamOrderEvents)
+	orders.GET("/:id/events", ctrl.StreamOrderEventsByID)
+	orders.GET("/ws", ctrl.ServeOrderSocket)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	t.Cleanup(ctrl.Shutdown)
+	return &eventsFixture{server: server, stream: stream, service: service}
+}
+
+func (f *eventsFixture) publish(t *testing.T, orderID, customerID string, status dm.OrderStatus) {
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+	event := kafkaDto.NewOrderStatusChangedEvent(orderID, customerID, dm.StatusNew, status)
+	require.NoError(t, f.stream.PublishOrderStatusChanged(ctx, event))
+}
+
+// open opens an event stream, closed when the test ends
+func (f *eventsFixture) open(t *testing.T, path string, headers map[string]string) *http.Response {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.server.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// sseEvent is an event read from a Server-Sent Events stream
+type sseEvent struct {
+	id, event, data string
+}
+
+// readEvents reads n events from a Server-Sent Events stream
+func readEvents(t *testing.T, resp *http.Response, n int) []sseEvent {
+	read := make(chan []sseEvent, 1)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var received []sseEvent
+		var current sseEvent
+		for len(received) < n && scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				received = append(received, current)
+				current = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				current.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				current.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				current.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		read <- received
+	}()
+
+	select {
+	case received := <-read:
+		require.Len(t, received, n)
+		return received
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "events not received")
+		return nil
+	}
+}
+
+func TestStreamOrderEvents(t *testing.T) {
+	f := newEventsFixture(t)
+
+	resp := f.open(t, "/api/v1/orders/events?customer_id=customer-1&status=DELIVERED", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	f.publish(t, "ORD-1", "customer-2", dm.StatusDelivered)
+	f.publish(t, "ORD-2", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-3", "customer-1", dm.StatusDelivered)
+
+	received := readEvents(t, resp, 1)
+	assert.Equal(t, "order.status_changed", received[0].event)
+	assert.True(t, events.IsValidID(received[0].id))
+	assert.Contains(t, received[0].data, `"order_id":"ORD-3"`)
+	assert.Contains(t, received[0].data, `"new_status":"DELIVERED"`)
+}
+
+func TestStreamOrderEventsResumes(t *testing.T) {
+	f := newEventsFixture(t)
+
+	first := f.open(t, "/api/v1/orders/events", nil)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+	lastEventID := readEvents(t, first, 1)[0].id
+
+	// Events appended while the client was away are sent first
+	f.publish(t, "ORD-2", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-3", "customer-1", dm.StatusInProgress)
+
+	resumed := f.open(t, "/api/v1/orders/events", map[string]string{"Last-Event-ID": lastEventID})
+	f.publish(t, "ORD-4", "customer-1", dm.StatusInProgress)
+
+	received := readEvents(t, resumed, 3)
+	for i, orderID := range []string{"ORD-2", "ORD-3", "ORD-4"} {
+		assert.Contains(t, received[i].data, `"order_id":"`+orderID+`"`)
+	}
+}
+
+func TestStreamOrderEventsResumesPastTheReplayLimit(t *testing.T) {
+	f := newEventsFixtureWithReplayLimit(t, 2)
+
+	first := f.open(t, "/api/v1/orders/events", nil)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+	lastEventID := readEvents(t, first, 1)[0].id
+
+	// Five missed events are replayed in pages of two
+	for _, orderID := range []string{"ORD-2", "ORD-3", "ORD-4", "ORD-5", "ORD-6"} {
+		f.publish(t, orderID, "customer-1", dm.StatusInProgress)
+	}
+
+	resumed := f.open(t, "/api/v1/orders/events", map[string]string{"Last-Event-ID": lastEventID})
+	f.publish(t, "ORD-7", "customer-1", dm.StatusInProgress)
+
+	received := readEvents(t, resumed, 6)
+	for i, orderID := range []string{"ORD-2", "ORD-3", "ORD-4", "ORD-5", "ORD-6", "ORD-7"} {
+		assert.Contains(t, received[i].data, `"order_id":"`+orderID+`"`)
+	}
+}
+
+func TestStreamOrderEventsByID(t *testing.T) {
+	f := newEventsFixture(t)
+	f.service.On("GetOrder", mock.Anything, "ORD-1", mock.Anything).Return(&api.OrderResponse{OrderID: "ORD-1"}, nil)
+	f.service.On("GetOrder", mock.Anything, "ORD-404", mock.Anything).Return(nil, customerrors.ErrOrderNotFound)
+
+	resp := f.open(t, "/api/v1/orders/ORD-1/events", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	f.publish(t, "ORD-2", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+
+	received := readEvents(t, resp, 1)
+	assert.Contains(t, received[0].data, `"order_id":"ORD-1"`)
+
+	notFound := f.open(t, "/api/v1/orders/ORD-404/events", nil)
+	assert.Equal(t, http.StatusNotFound, notFound.StatusCode)
+}
+
+func TestStreamOrderEventsValidation(t *testing.T) {
+	f := newEventsFixture(t)
+
+	for path, code := range map[string]string{
+		"/api/v1/orders/events?status=LOST":             "INVALID_STATUS",
+		"/api/v1/orders/events?last_event_id=yesterday": "INVALID_EVENT_ID",
+	} {
+		resp := f.open(t, path, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+		body := new(strings.Builder)
+		_, _ = bufio.NewReader(resp.Body).WriteTo(body)
+		assert.Contains(t, body.String(), code, path)
+	}
+}
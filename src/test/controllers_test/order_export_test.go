@@ -0,0 +1,214 @@
+package controllers_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+)
+
+// exportFixture serves the export route over a real server, which compressed and aborted
+// exports need
+type exportFixture struct {
+	server  *httptest.Server
+	service *mockOrderService
+}
+
+func newExportFixture(t *testing.T) *exportFixture {
+	service := new(mockOrderService)
+	ctrl := controllers.NewOrderController(service, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/api/v1/orders/export", ctrl.ExportOrders)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return &exportFixture{server: server, service: service}
+}
+
+// get requests an export, decompressing it only when asked to accept gzip
+func (f *exportFixture) get(t *testing.T, query string, acceptGzip bool) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, f.server.URL+"/api/v1/orders/export"+query, nil)
+	require.NoError(t, err)
+	if acceptGzip {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func exportedOrders(read dm.ReadOptions) []*api.OrderResponse {
+	created := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	return []*api.OrderResponse{
+		api.NewOrderResponseWith(&dm.Order{
+			OrderID: "ORD-1", CustomerID: "customer-1", CustomerName: "Ada, Lovelace", Status: dm.StatusDelivered,
+			Items: []dm.OrderItem{{Sku: "SKU-1", Quantity: 2, Price: 10.5}}, Total: 21, CreatedAt: created, UpdatedAt: created,
+		}, read),
+		api.NewOrderResponseWith(&dm.Order{
+			OrderID: "ORD-2", CustomerID: "customer-2", Status: dm.StatusDelivered, Total: 5.25, CreatedAt: created, UpdatedAt: created,
+		}, read),
+	}
+}
+
+func TestExportOrdersCSV(t *testing.T) {
+	f := newExportFixture(t)
+	filter := dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusDelivered}}
+	sort := []dm.SortField{{Field: "total", Descending: true}}
+	f.service.On("ExportOrders", mock.Anything, filter, sort, dm.ReadOptions{}).Return(exportedOrders(dm.ReadOptions{}), nil)
+
+	resp := f.get(t, "?status=DELIVERED&sort=-total", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `.csv"`)
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, api.ExportColumns, records[0])
+	assert.Equal(t, []string{
+		"ORD-1", "customer-1", "Ada, Lovelace", "", "DELIVERED", `[{"sku":"SKU-1","quantity":2,"price":10.5}]`,
+		"21", "2026-09-01T10:00:00Z", "2026-09-01T10:00:00Z", "",
+	}, records[1])
+	assert.Equal(t, "5.25", records[2][6])
+}
+
+func TestExportOrdersIncludesArchivedOnRequest(t *testing.T) {
+	f := newExportFixture(t)
+	delivered := dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusDelivered}}
+	archived := dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusDelivered}, IncludeArchived: true}
+	f.service.On("ExportOrders", mock.Anything, delivered, mock.Anything, mock.Anything).Return(exportedOrders(dm.ReadOptions{}), nil).Once()
+	f.service.On("ExportOrders", mock.Anything, archived, mock.Anything, mock.Anything).Return(exportedOrders(dm.ReadOptions{}), nil).Once()
+
+	resp := f.get(t, "?status=DELIVERED", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = f.get(t, "?status=DELIVERED&include_archived=true", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	f.service.AssertExpectations(t)
+}
+
+func TestExportOrdersCSVEscapesFormulas(t *testing.T) {
+	f := newExportFixture(t)
+	read := dm.ReadOptions{Fields: []string{"customer_id", "customer_name", "shipping_address", "total"}}
+	f.service.On("ExportOrders", mock.Anything, mock.Anything, mock.Anything, read).Return([]*api.OrderResponse{
+		api.NewOrderResponseWith(&dm.Order{CustomerID: "@customer", CustomerName: "=HYPERLINK(\"https://evil.example.com\")", ShippingAddress: "+1 Main St", Total: -3}, read),
+		api.NewOrderResponseWith(&dm.Order{CustomerID: "customer-2", CustomerName: "-Ada", ShippingAddress: "\t1 Main St"}, read),
+		api.NewOrderResponseWith(&dm.Order{CustomerID: "customer-3", CustomerName: "Ada = Lovelace", ShippingAddress: "\r1 Main St"}, read),
+	}, nil)
+
+	resp := f.get(t, "?fields=customer_id,customer_name,shipping_address,total", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"'@customer", "'=HYPERLINK(\"https://evil.example.com\")", "'+1 Main St", "-3"}, records[1])
+	assert.Equal(t, []string{"customer-2", "'-Ada", "'\t1 Main St", "0"}, records[2])
+	assert.Equal(t, []string{"customer-3", "Ada = Lovelace", "'\r1 Main St", "0"}, records[3])
+}
+
+func TestExportOrdersSelectedColumnsGzip(t *testing.T) {
+	f := newExportFixture(t)
+	read := dm.ReadOptions{Fields: []string{"status", "order_id"}}
+	f.service.On("ExportOrders", mock.Anything, dm.OrderFilter{}, mock.Anything, read).Return(exportedOrders(read), nil)
+
+	resp := f.get(t, "?fields=status,order_id", true)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	body, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "status,order_id\nDELIVERED,ORD-1\nDELIVERED,ORD-2\n", string(content))
+}
+
+func TestExportOrdersNDJSON(t *testing.T) {
+	f := newExportFixture(t)
+	read := dm.ReadOptions{Fields: []string{"order_id", "total"}}
+	f.service.On("ExportOrders", mock.Anything, dm.OrderFilter{CustomerID: "customer-1"}, mock.Anything, read).Return(exportedOrders(read), nil)
+
+	resp := f.get(t, "?format=ndjson&customer_id=customer-1&fields=order_id,total", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Equal(t, []string{`{"order_id":"ORD-1","total":21}`, `{"order_id":"ORD-2","total":5.25}`}, lines)
+}
+
+func TestExportOrdersEmpty(t *testing.T) {
+	f := newExportFixture(t)
+	f.service.On("ExportOrders", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	resp := f.get(t, "?fields=order_id", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "order_id\n", string(content))
+}
+
+func TestExportOrdersErrors(t *testing.T) {
+	f := newExportFixture(t)
+	f.service.On("ExportOrders", mock.Anything, dm.OrderFilter{CustomerID: "forbidden"}, mock.Anything, mock.Anything).Return(nil, customerrors.ErrForbidden)
+	f.service.On("ExportOrders", mock.Anything, dm.OrderFilter{CustomerID: "broken"}, mock.Anything, mock.Anything).Return(nil, errors.New("connection reset"))
+
+	for query, expected := range map[string]struct {
+		status int
+		code   string
+	}{
+		"?format=xlsx":                      {http.StatusBadRequest, "INVALID_FORMAT"},
+		"?expand=history":                   {http.StatusBadRequest, "INVALID_EXPAND"},
+		"?fields=password":                  {http.StatusBadRequest, "INVALID_FIELDS"},
+		"?status=LOST":                      {http.StatusBadRequest, "INVALID_STATUS"},
+		"?sort=customer_name":               {http.StatusBadRequest, "INVALID_SORT"},
+		"?customer_id=forbidden":            {http.StatusForbidden, "FORBIDDEN"},
+		"?customer_id=broken&format=ndjson": {http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"},
+	} {
+		resp := f.get(t, query, false)
+		assert.Equal(t, expected.status, resp.StatusCode, query)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), expected.code, query)
+	}
+}
+
+func TestExportOrdersAbortsMidway(t *testing.T) {
+	f := newExportFixture(t)
+	f.service.On("ExportOrders", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(exportedOrders(dm.ReadOptions{}), errors.New("cursor killed"))
+
+	// Rows still buffered when the cursor fails are dropped along with the connection, so the
+	// client cannot mistake the partial export for a complete one
+	resp, err := http.Get(f.server.URL + "/api/v1/orders/export")
+	if err == nil {
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+	}
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF) || strings.HasSuffix(err.Error(), "EOF"), err)
+}
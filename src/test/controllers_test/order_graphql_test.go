@@ -0,0 +1,183 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/controllers"
+	"order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+)
+
+// Helper function to create a test router with the GraphQL controller
+func setupGraphQLRouter(t *testing.T, mockSvc *mockOrderService) *gin.Engine {
+	ctrl, err := controllers.NewGraphQLController(mockSvc, zap.NewNop())
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(customerrors.ErrorHandler())
+	r.POST("/graphql", ctrl.Query)
+	r.GET("/graphql", ctrl.Query)
+	return r
+}
+
+// graphQLResponse is the decoded body of a GraphQL response
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, router *gin.Engine, query string, variables map[string]interface{}) (int, graphQLResponse) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+// sameIDs matches a batch of IDs in any order
+func sameIDs(expected ...string) interface{} {
+	return mock.MatchedBy(func(ids []string) bool {
+		sorted := append([]string(nil), ids...)
+		sort.Strings(sorted)
+		sort.Strings(expected)
+		return assert.ObjectsAreEqual(expected, sorted)
+	})
+}
+
+func TestGraphQLBatchesOrderAndCustomerLookups(t *testing.T) {
+	first := createTestOrderResponse()
+	second := createTestOrderResponse()
+	second.OrderID = "order-456"
+
+	mockSvc := &mockOrderService{}
+	mockSvc.On("GetOrdersByIDs", mock.Anything, sameIDs("order-123", "order-456", "missing"), mock.Anything).
+		Return([]*api.OrderResponse{first, second}, nil).Once()
+	mockSvc.On("GetCustomerSummaries", mock.Anything, []string{"customer-123"}).
+		Return([]*api.CustomerSummaryResponse{{CustomerID: "customer-123", OrderCount: 2, TotalSpent: 43.96}}, nil).Once()
+
+	router := setupGraphQLRouter(t, mockSvc)
+	code, resp := postGraphQL(t, router, `{
+		a: order(id: "order-123") { orderId total customer { orderCount totalSpent } }
+		b: order(id: "order-456") { orderId items { sku quantity } customer { customerId } }
+		c: order(id: "missing") { orderId }
+	}`, nil)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"orderId":"order-123","total":21.98,"customer":{"orderCount":2,"totalSpent":43.96}}`, string(resp.Data["a"]))
+	assert.JSONEq(t, `{"orderId":"order-456","items":[{"sku":"SKU-123","quantity":2}],"customer":{"customerId":"customer-123"}}`, string(resp.Data["b"]))
+	assert.JSONEq(t, `null`, string(resp.Data["c"]))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "ORDER_NOT_FOUND", resp.Errors[0].Extensions["code"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestGraphQLListOrders(t *testing.T) {
+	total := int64(1)
+	mockSvc := &mockOrderService{}
+	mockSvc.On("ListOrders", mock.Anything,
+		dm.OrderFilter{Statuses: []dm.OrderStatus{dm.StatusNew}, CustomerID: "customer-123"},
+		mock.MatchedBy(func(opts dm.ListOptions) bool {
+			return opts.CursorMode && opts.Limit == 5 && !opts.SkipTotal && opts.Cursor == nil
+		})).
+		Return(&api.ListOrdersResponse{
+			Data:       []*api.OrderResponse{createTestOrderResponse()},
+			Limit:      5,
+			Total:      &total,
+			HasMore:    true,
+			NextCursor: "next",
+		}, nil)
+
+	router := setupGraphQLRouter(t, mockSvc)
+	code, resp := postGraphQL(t, router, `query($customer: ID) {
+		orders(status: [NEW], customerId: $customer, first: 5, includeTotal: true) {
+			nodes { orderId status }
+			total hasMore nextCursor prevCursor
+		}
+	}`, map[string]interface{}{"customer": "customer-123"})
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"nodes":[{"orderId":"order-123","status":"NEW"}],"total":1,"hasMore":true,"nextCursor":"next","prevCursor":null}`, string(resp.Data["orders"]))
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, resp := postGraphQL(t, router, `{ orders(after: "not-a-cursor") { hasMore } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "INVALID_CURSOR", resp.Errors[0].Extensions["code"])
+	})
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	t.Run("create order", func(t *testing.T) {
+		mockSvc := &mockOrderService{}
+		mockSvc.On("CreateOrder", mock.Anything, mock.MatchedBy(func(order *dm.Order) bool {
+			return order.CustomerID == "customer-123" && len(order.Items) == 1 && order.Items[0].Quantity == 2
+		})).Return(createTestOrderResponse(), nil)
+
+		code, resp := postGraphQL(t, setupGraphQLRouter(t, mockSvc), `mutation {
+			createOrder(input: {customerId: "customer-123", items: [{sku: "SKU-123", quantity: 2}]}) { orderId status }
+		}`, nil)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"orderId":"order-123","status":"NEW"}`, string(resp.Data["createOrder"]))
+	})
+
+	t.Run("create order with invalid items", func(t *testing.T) {
+		mockSvc := &mockOrderService{}
+
+		_, resp := postGraphQL(t, setupGraphQLRouter(t, mockSvc), `mutation {
+			createOrder(input: {customerId: "customer-123", items: [{sku: "SKU-123", quantity: 0}]}) { orderId }
+		}`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "VALIDATION_ERROR", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"field": "items[0].quantity", "message": "must be greater than 0"}},
+			resp.Errors[0].Extensions["errors"])
+		mockSvc.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("update status with an invalid transition", func(t *testing.T) {
+		mockSvc := &mockOrderService{}
+		mockSvc.On("UpdateOrderStatus", mock.Anything, "order-123", dm.StatusDelivered).
+			Return(customerrors.ErrInvalidTransition)
+
+		_, resp := postGraphQL(t, setupGraphQLRouter(t, mockSvc), `mutation {
+			updateOrderStatus(id: "order-123", status: DELIVERED) { status }
+		}`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "invalid status transition", resp.Errors[0].Message)
+		assert.Equal(t, "INVALID_TRANSITION", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("mutations are rejected over GET", func(t *testing.T) {
+		query := url.Values{"query": {`mutation { updateOrderStatus(id: "order-123", status: DELIVERED) { status } }`}}
+		req := httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil)
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		setupGraphQLRouter(t, &mockOrderService{}).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.JSONEq(t, `{"code":"METHOD_NOT_ALLOWED","message":"mutations require POST"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,152 @@
+package controllers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+)
+
+// dial opens the order WebSocket, closed when the test ends
+func (f *eventsFixture) dial(t *testing.T) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(f.server.URL, "http") + "/api/v1/orders/ws"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// request sends a request over the order WebSocket and reads the messages until its answer
+func request(t *testing.T, conn *websocket.Conn, req api.SocketRequest) (api.SocketMessage, []api.SocketMessage) {
+	require.NoError(t, conn.WriteJSON(req))
+	var received []api.SocketMessage
+	for {
+		msg := readMessage(t, conn)
+		if msg.ID == req.ID && msg.Type != api.SocketEvent {
+			return msg, received
+		}
+		received = append(received, msg)
+	}
+}
+
+// readMessage reads a message from the order WebSocket
+func readMessage(t *testing.T, conn *websocket.Conn) api.SocketMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg api.SocketMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestOrderSocketSubscribe(t *testing.T) {
+	f := newEventsFixture(t)
+	f.service.On("GetOrder", mock.Anything, "ORD-1", mock.Anything).Return(&api.OrderResponse{OrderID: "ORD-1"}, nil)
+	conn := f.dial(t)
+
+	ack, _ := request(t, conn, api.SocketRequest{Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-1"}})
+	require.Equal(t, api.SocketAck, ack.Type)
+
+	f.publish(t, "ORD-2", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+
+	event := readMessage(t, conn)
+	assert.Equal(t, api.SocketEvent, event.Type)
+	assert.Equal(t, "order.status_changed", event.Event)
+	assert.Equal(t, "ORD-1", event.Data.(map[string]interface{})["order_id"])
+
+	// Events of unsubscribed orders are no longer sent
+	ack, _ = request(t, conn, api.SocketRequest{Type: api.SocketUnsubscribe, ID: "2", OrderIDs: []string{"ORD-1"}})
+	require.Equal(t, api.SocketAck, ack.Type)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusDelivered)
+	ack, received := request(t, conn, api.SocketRequest{Type: api.SocketUnsubscribe, ID: "3"})
+	assert.Equal(t, api.SocketAck, ack.Type)
+	assert.Empty(t, received)
+}
+
+func TestOrderSocketResumes(t *testing.T) {
+	f := newEventsFixture(t)
+	f.service.On("GetOrder", mock.Anything, "ORD-1", mock.Anything).Return(&api.OrderResponse{OrderID: "ORD-1"}, nil)
+
+	first := f.dial(t)
+	request(t, first, api.SocketRequest{Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-1"}})
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+	lastEventID := readMessage(t, first).EventID
+
+	// Events appended while the client was away are sent before the acknowledgement
+	f.publish(t, "ORD-2", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusDelivered)
+
+	resumed := f.dial(t)
+	ack, received := request(t, resumed, api.SocketRequest{Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-1"}, LastEventID: lastEventID})
+	require.Equal(t, api.SocketAck, ack.Type)
+	require.Len(t, received, 1)
+	assert.Equal(t, "DELIVERED", received[0].Data.(map[string]interface{})["new_status"])
+}
+
+func TestOrderSocketResumesPastTheReplayLimit(t *testing.T) {
+	f := newEventsFixtureWithReplayLimit(t, 1)
+	f.service.On("GetOrder", mock.Anything, "ORD-1", mock.Anything).Return(&api.OrderResponse{OrderID: "ORD-1"}, nil)
+
+	first := f.dial(t)
+	request(t, first, api.SocketRequest{Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-1"}})
+	f.publish(t, "ORD-1", "customer-1", dm.StatusNew)
+	lastEventID := readMessage(t, first).EventID
+
+	// Three missed events are replayed one at a time
+	f.publish(t, "ORD-1", "customer-1", dm.StatusInProgress)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusDelivered)
+	f.publish(t, "ORD-1", "customer-1", dm.StatusCancelled)
+
+	resumed := f.dial(t)
+	ack, received := request(t, resumed, api.SocketRequest{Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-1"}, LastEventID: lastEventID})
+	require.Equal(t, api.SocketAck, ack.Type)
+	require.Len(t, received, 3)
+	for i, status := range []string{"IN_PROGRESS", "DELIVERED", "CANCELLED"} {
+		assert.Equal(t, status, received[i].Data.(map[string]interface{})["new_status"])
+	}
+}
+
+func TestOrderSocketRejectsRequests(t *testing.T) {
+	f := newEventsFixture(t)
+	f.service.On("GetOrder", mock.Anything, "ORD-404", mock.Anything).Return(nil, customerrors.ErrOrderNotFound)
+	conn := f.dial(t)
+
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = "ORD-1"
+	}
+	for code, req := range map[string]api.SocketRequest{
+		"ORDER_NOT_FOUND":  {Type: api.SocketSubscribe, ID: "1", OrderIDs: []string{"ORD-404"}},
+		"INVALID_ORDER_ID": {Type: api.SocketSubscribe, ID: "2"},
+		"INVALID_EVENT_ID": {Type: api.SocketSubscribe, ID: "3", OrderIDs: []string{"ORD-1"}, LastEventID: "yesterday"},
+		"TOO_MANY_ORDERS":  {Type: api.SocketSubscribe, ID: "4", OrderIDs: tooMany},
+		"INVALID_REQUEST":  {Type: "ship", ID: "5"},
+	} {
+		answer, _ := request(t, conn, req)
+		assert.Equal(t, api.SocketError, answer.Type, code)
+		assert.Equal(t, code, answer.Code)
+	}
+}
+
+func TestOrderSocketDeliver(t *testing.T) {
+	f := newEventsFixture(t)
+	f.service.On("UpdateOrderStatus", mock.Anything, "ORD-1", dm.StatusDelivered).Return(nil)
+	f.service.On("UpdateOrderStatus", mock.Anything, "ORD-2", dm.StatusDelivered).Return(customerrors.ErrInvalidTransition)
+	conn := f.dial(t)
+
+	ack, _ := request(t, conn, api.SocketRequest{Type: api.SocketDeliver, ID: "1", OrderID: "ORD-1"})
+	assert.Equal(t, api.SocketAck, ack.Type)
+
+	rejected, _ := request(t, conn, api.SocketRequest{Type: api.SocketDeliver, ID: "2", OrderID: "ORD-2"})
+	assert.Equal(t, api.SocketError, rejected.Type)
+	assert.Equal(t, customerrors.ErrInvalidTransition.ErrorCode(), rejected.Code)
+	f.service.AssertExpectations(t)
+}
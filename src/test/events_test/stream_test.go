@@ -0,0 +1,157 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	dm "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/events"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+var eventsConfig = config.Events{Stream: "order_events", StreamMaxLen: 100, Buffer: 2, ReplayLimit: 10}
+
+// startStream runs a stream on miniredis and waits until it reads the events appended to it
+func startStream(t *testing.T) (*events.Stream, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	stream := events.NewStream(client, eventsConfig, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go stream.Run(ctx)
+
+	warmup := stream.Subscribe(events.Filter{TenantID: "warmup"})
+	defer stream.Unsubscribe(warmup)
+	require.Eventually(t, func() bool {
+		publish(t, stream, "warmup", "ORD-0", "customer-0", dm.StatusInProgress)
+		select {
+		case <-warmup.Events():
+			return true
+		case <-time.After(20 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, time.Millisecond)
+	return stream, server
+}
+
+func publish(t *testing.T, stream *events.Stream, tenantID, orderID, customerID string, status dm.OrderStatus) {
+	ctx := tenant.NewContext(context.Background(), tenantID)
+	event := kafkaDto.NewOrderStatusChangedEvent(orderID, customerID, dm.StatusNew, status)
+	require.NoError(t, stream.PublishOrderStatusChanged(ctx, event))
+}
+
+func receive(t *testing.T, sub *events.Subscription) events.Event {
+	select {
+	case event, ok := <-sub.Events():
+		require.True(t, ok, "subscription closed")
+		return event
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "no event received")
+		return events.Event{}
+	}
+}
+
+func TestStreamPushesMatchingEvents(t *testing.T) {
+	stream, _ := startStream(t)
+	sub := stream.Subscribe(events.Filter{TenantID: "brand-a", CustomerID: "customer-1", Statuses: []dm.OrderStatus{dm.StatusDelivered}})
+	defer stream.Unsubscribe(sub)
+
+	publish(t, stream, "brand-b", "ORD-1", "customer-1", dm.StatusDelivered)
+	publish(t, stream, "brand-a", "ORD-2", "customer-2", dm.StatusDelivered)
+	publish(t, stream, "brand-a", "ORD-3", "customer-1", dm.StatusInProgress)
+	publish(t, stream, "brand-a", "ORD-4", "customer-1", dm.StatusDelivered)
+
+	event := receive(t, sub)
+	assert.Equal(t, "ORD-4", event.OrderID)
+	assert.Equal(t, "brand-a", event.TenantID)
+	assert.Equal(t, "customer-1", event.CustomerID)
+	assert.Equal(t, dm.StatusDelivered, event.NewStatus)
+	assert.True(t, events.IsValidID(event.ID))
+}
+
+func TestStreamReplaysMissedEvents(t *testing.T) {
+	stream, _ := startStream(t)
+	sub := stream.Subscribe(events.Filter{TenantID: "brand-a"})
+	defer stream.Unsubscribe(sub)
+
+	publish(t, stream, "brand-a", "ORD-1", "customer-1", dm.StatusInProgress)
+	first := receive(t, sub)
+	publish(t, stream, "brand-b", "ORD-2", "customer-2", dm.StatusInProgress)
+	publish(t, stream, "brand-a", "ORD-3", "customer-1", dm.StatusInProgress)
+	publish(t, stream, "brand-a", "ORD-4", "customer-1", dm.StatusInProgress)
+
+	missed, err := stream.Replay(context.Background(), events.Filter{TenantID: "brand-a"}, first.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, missed, 2)
+	assert.Equal(t, "ORD-3", missed[0].OrderID)
+	assert.Equal(t, "ORD-4", missed[1].OrderID)
+	assert.True(t, events.IsAfter(missed[1].ID, missed[0].ID))
+
+	limited, err := stream.Replay(context.Background(), events.Filter{TenantID: "brand-a"}, first.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+}
+
+func TestStreamDropsSlowSubscribers(t *testing.T) {
+	stream, _ := startStream(t)
+	slow := stream.Subscribe(events.Filter{TenantID: "brand-a"})
+	defer stream.Unsubscribe(slow)
+
+	// The buffer holds two events, the third one drops the subscriber
+	for _, orderID := range []string{"ORD-1", "ORD-2", "ORD-3"} {
+		publish(t, stream, "brand-a", orderID, "customer-1", dm.StatusInProgress)
+	}
+
+	assert.Equal(t, "ORD-1", receive(t, slow).OrderID)
+	assert.Equal(t, "ORD-2", receive(t, slow).OrderID)
+	assert.Eventually(t, func() bool {
+		_, ok := <-slow.Events()
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamSetFilter(t *testing.T) {
+	stream, _ := startStream(t)
+	sub := stream.Subscribe(events.Filter{TenantID: "brand-a", OrderIDs: []string{"ORD-1"}})
+	defer stream.Unsubscribe(sub)
+
+	stream.SetFilter(sub, events.Filter{TenantID: "brand-a", OrderIDs: []string{"ORD-2"}})
+
+	// The events of the other orders no longer fill the buffer of two events
+	for _, orderID := range []string{"ORD-1", "ORD-1", "ORD-1", "ORD-2"} {
+		publish(t, stream, "brand-a", orderID, "customer-1", dm.StatusInProgress)
+	}
+	assert.Equal(t, "ORD-2", receive(t, sub).OrderID)
+}
+
+func TestStreamCloseEndsSubscriptions(t *testing.T) {
+	stream, _ := startStream(t)
+	sub := stream.Subscribe(events.Filter{TenantID: "brand-a"})
+
+	stream.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	_, ok = <-stream.Subscribe(events.Filter{TenantID: "brand-a"}).Events()
+	assert.False(t, ok)
+}
+
+func TestIsAfter(t *testing.T) {
+	assert.True(t, events.IsAfter("1700000000000-0", ""))
+	assert.True(t, events.IsAfter("1700000000000-1", "1700000000000-0"))
+	assert.True(t, events.IsAfter("1700000000001-0", "1700000000000-9"))
+	assert.False(t, events.IsAfter("1700000000000-0", "1700000000000-0"))
+	assert.False(t, events.IsAfter("999-0", "1000-0"))
+	assert.False(t, events.IsValidID("1700000000000"))
+}
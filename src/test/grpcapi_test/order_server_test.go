@@ -0,0 +1,389 @@
+package grpcapi_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/models/api"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/auth"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/grpcapi"
+	"order-management-ms/src/main/pkg/tenant"
+	ordersv1 "order-management-ms/src/main/proto/orders/v1"
+	"order-management-ms/src/main/services/orders"
+)
+
+// fakeOrderService keeps orders in memory, recording the tenant and the access of each call
+type fakeOrderService struct {
+	orders.Service
+
+	mu      sync.Mutex
+	orders  map[string]*api.OrderResponse
+	tenants []string
+	access  *authz.Access
+	filter  dm.OrderFilter
+	opts    dm.ListOptions
+}
+
+func (f *fakeOrderService) record(ctx context.Context) {
+	tenantID, _ := tenant.FromContext(ctx)
+	f.tenants = append(f.tenants, tenantID)
+	f.access, _ = authz.FromContext(ctx)
+}
+
+func (f *fakeOrderService) CreateOrder(ctx context.Context, order *dm.Order) (*api.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(ctx)
+
+	// Customers may only order for themselves, like in the order service
+	customerID, err := authz.WriteScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if customerID != "" && order.CustomerID != customerID {
+		return nil, customerrors.ErrForbidden
+	}
+
+	order.OrderID = "ORD-1"
+	order.Status = dm.StatusNew
+	response := api.NewOrderResponse(order)
+	f.orders[order.OrderID] = response
+	return response, nil
+}
+
+func (f *fakeOrderService) GetOrder(ctx context.Context, orderID string, read dm.ReadOptions) (*api.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(ctx)
+
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, customerrors.ErrOrderNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (f *fakeOrderService) ListOrders(ctx context.Context, filter dm.OrderFilter, opts dm.ListOptions) (*api.ListOrdersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(ctx)
+
+	f.filter, f.opts = filter, opts
+	return &api.ListOrdersResponse{Data: []*api.OrderResponse{f.orders["ORD-1"]}, HasMore: true, NextCursor: "next"}, nil
+}
+
+func (f *fakeOrderService) UpdateOrderStatus(ctx context.Context, orderID string, newStatus dm.OrderStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(ctx)
+
+	order, ok := f.orders[orderID]
+	if !ok {
+		return customerrors.ErrOrderNotFound
+	}
+	if order.Status == string(dm.StatusDelivered) {
+		return customerrors.ErrInvalidTransition
+	}
+	order.Status = string(newStatus)
+	return nil
+}
+
+// startServer serves the gRPC API over an in-memory connection
+func startServer(t *testing.T, svc orders.Service) *grpc.ClientConn {
+	return startServerWithAuth(t, svc, nil, nil)
+}
+
+// startServerWithAuth serves the gRPC API, authenticating the order calls with the verifier
+// and the API keys
+func startServerWithAuth(t *testing.T, svc orders.Service, verifier *auth.Verifier, keys apikey.Authenticator) *grpc.ClientConn {
+	cfg := &config.Config{
+		GRPC:    config.GRPC{WatchInterval: 10 * time.Millisecond},
+		Tenant:  config.Tenant{Header: "X-Tenant-ID"},
+		APIKeys: config.APIKeys{Header: "X-API-Key"},
+	}
+	srv := grpcapi.NewServer(cfg, svc, verifier, keys, authz.DefaultPolicy(), zap.NewNop())
+
+	listener := bufconn.Listen(1024 * 1024)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withTenant(tenantID string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", tenantID)
+}
+
+func TestOrderServer(t *testing.T) {
+	svc := &fakeOrderService{orders: map[string]*api.OrderResponse{}}
+	client := ordersv1.NewOrderServiceClient(startServer(t, svc))
+	ctx := withTenant("brand-a")
+
+	created, err := client.CreateOrder(ctx, &ordersv1.CreateOrderRequest{
+		CustomerId: "customer-123",
+		Items:      []*ordersv1.CreateOrderItem{{Sku: "SKU-123", Quantity: 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", created.OrderId)
+	assert.Equal(t, ordersv1.OrderStatus_ORDER_STATUS_NEW, created.Status)
+	assert.Len(t, created.Items, 1)
+
+	order, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "customer-123", order.CustomerId)
+
+	minTotal := 10.0
+	page, err := client.ListOrders(ctx, &ordersv1.ListOrdersRequest{
+		Statuses: []ordersv1.OrderStatus{ordersv1.OrderStatus_ORDER_STATUS_NEW},
+		MinTotal: &minTotal,
+		PageSize: 5,
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Orders, 1)
+	assert.Equal(t, "next", page.NextPageToken)
+	assert.Equal(t, []dm.OrderStatus{dm.StatusNew}, svc.filter.Statuses)
+	assert.Equal(t, &minTotal, svc.filter.MinTotal)
+	assert.True(t, svc.opts.CursorMode)
+	assert.Equal(t, 5, svc.opts.Limit)
+	assert.True(t, svc.opts.SkipTotal)
+
+	updated, err := client.UpdateOrderStatus(ctx, &ordersv1.UpdateOrderStatusRequest{OrderId: "ORD-1", Status: ordersv1.OrderStatus_ORDER_STATUS_DELIVERED})
+	require.NoError(t, err)
+	assert.Equal(t, ordersv1.OrderStatus_ORDER_STATUS_DELIVERED, updated.Status)
+
+	for _, tenantID := range svc.tenants {
+		assert.Equal(t, "brand-a", tenantID)
+	}
+}
+
+func TestOrderServerErrors(t *testing.T) {
+	svc := &fakeOrderService{orders: map[string]*api.OrderResponse{
+		"ORD-1": {OrderID: "ORD-1", Status: string(dm.StatusDelivered)},
+	}}
+	client := ordersv1.NewOrderServiceClient(startServer(t, svc))
+
+	tests := []struct {
+		name   string
+		call   func() error
+		code   codes.Code
+		reason string
+	}{
+		{
+			name: "missing tenant",
+			call: func() error {
+				_, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "MISSING_TENANT",
+		},
+		{
+			name: "order not found",
+			call: func() error {
+				_, err := client.GetOrder(withTenant("brand-a"), &ordersv1.GetOrderRequest{OrderId: "missing"})
+				return err
+			},
+			code:   codes.NotFound,
+			reason: "ORDER_NOT_FOUND",
+		},
+		{
+			name: "invalid transition",
+			call: func() error {
+				_, err := client.UpdateOrderStatus(withTenant("brand-a"), &ordersv1.UpdateOrderStatusRequest{OrderId: "ORD-1", Status: ordersv1.OrderStatus_ORDER_STATUS_NEW})
+				return err
+			},
+			code:   codes.FailedPrecondition,
+			reason: "INVALID_TRANSITION",
+		},
+		{
+			name: "unspecified status",
+			call: func() error {
+				_, err := client.UpdateOrderStatus(withTenant("brand-a"), &ordersv1.UpdateOrderStatusRequest{OrderId: "ORD-1"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "INVALID_STATUS",
+		},
+		{
+			name: "invalid page token",
+			call: func() error {
+				_, err := client.ListOrders(withTenant("brand-a"), &ordersv1.ListOrdersRequest{PageToken: "not-a-cursor"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "INVALID_CURSOR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(tt.call())
+			assert.Equal(t, tt.code, st.Code())
+			require.Len(t, st.Details(), 1)
+			assert.Equal(t, tt.reason, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+		})
+	}
+}
+
+func TestWatchOrder(t *testing.T) {
+	svc := &fakeOrderService{orders: map[string]*api.OrderResponse{
+		"ORD-1": {OrderID: "ORD-1", Status: string(dm.StatusNew)},
+	}}
+	client := ordersv1.NewOrderServiceClient(startServer(t, svc))
+
+	stream, err := client.WatchOrder(withTenant("brand-a"), &ordersv1.WatchOrderRequest{OrderId: "ORD-1"})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, ordersv1.OrderStatus_ORDER_STATUS_NEW, first.Status)
+
+	require.NoError(t, svc.UpdateOrderStatus(tenant.NewContext(context.Background(), "brand-a"), "ORD-1", dm.StatusInProgress))
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, ordersv1.OrderStatus_ORDER_STATUS_IN_PROGRESS, second.Status)
+
+	// The stream ends once the order reaches a final status
+	require.NoError(t, svc.UpdateOrderStatus(tenant.NewContext(context.Background(), "brand-a"), "ORD-1", dm.StatusDelivered))
+	third, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, ordersv1.OrderStatus_ORDER_STATUS_DELIVERED, third.Status)
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestHealth(t *testing.T) {
+	client := healthpb.NewHealthClient(startServer(t, &fakeOrderService{}))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: ordersv1.OrderService_ServiceDesc.ServiceName})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestOrderServerAuthentication(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := `{"keys":[{"kty":"oct","alg":"HS256","k":"` + base64.RawURLEncoding.EncodeToString(secret) + `"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+	keys, err := auth.NewKeySet(context.Background(), path, time.Hour)
+	require.NoError(t, err)
+
+	svc := &fakeOrderService{orders: map[string]*api.OrderResponse{"ORD-1": {OrderID: "ORD-1", Status: string(dm.StatusNew)}}}
+	conn := startServerWithAuth(t, svc, auth.NewVerifier(keys, config.Auth{}, "brand-a"), nil)
+	client := ordersv1.NewOrderServiceClient(conn)
+
+	_, err = client.GetOrder(withTenant("brand-a"), &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "customer-123",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"customer"},
+	}).SignedString(secret)
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(withTenant("brand-a"), "authorization", "Bearer "+token)
+
+	order, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", order.OrderId)
+	require.NotNil(t, svc.access)
+	assert.Equal(t, "customer-123", svc.access.CustomerID)
+	assert.True(t, svc.access.Can(authz.ReadOwnOrders))
+	assert.False(t, svc.access.Can(authz.ReadOrders))
+	assert.Equal(t, "brand-a", svc.tenants[len(svc.tenants)-1])
+
+	// The errors of the service keep their code
+	_, err = client.CreateOrder(ctx, &ordersv1.CreateOrderRequest{
+		CustomerId: "customer-456",
+		Items:      []*ordersv1.CreateOrderItem{{Sku: "SKU-123", Quantity: 2}},
+	})
+	st := status.Convert(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, "FORBIDDEN", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+
+	// The token binds the caller to its tenant, which the metadata cannot override
+	ctx = metadata.AppendToOutgoingContext(withTenant("brand-b"), "authorization", "Bearer "+token)
+	_, err = client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The health service stays public
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
+
+// stubKeys accepts a single API key of the brand-a tenant
+type stubKeys struct{}
+
+func (stubKeys) Authenticate(ctx context.Context, plainKey, clientIP string) (*dm.APIKey, error) {
+	if plainKey != "omk_1234_secret" {
+		return nil, customerrors.ErrInvalidAPIKey
+	}
+	return &dm.APIKey{KeyID: "1234", TenantID: "brand-a", Scopes: []string{"orders:read"}}, nil
+}
+
+func TestOrderServerAPIKeys(t *testing.T) {
+	svc := &fakeOrderService{orders: map[string]*api.OrderResponse{"ORD-1": {OrderID: "ORD-1", Status: string(dm.StatusNew)}}}
+	client := ordersv1.NewOrderServiceClient(startServerWithAuth(t, svc, nil, stubKeys{}))
+
+	// Without bearer tokens, API keys are the only way in
+	_, err := client.GetOrder(withTenant("brand-a"), &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "omk_1234_wrong")
+	_, err = client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "omk_1234_secret")
+	order, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", order.OrderId)
+	assert.Equal(t, "brand-a", svc.tenants[len(svc.tenants)-1])
+	require.NotNil(t, svc.access)
+	assert.True(t, svc.access.Can(authz.ReadOrders))
+	assert.False(t, svc.access.Can(authz.UpdateStatus))
+
+	// The key binds the caller to its tenant, which the metadata cannot override
+	ctx = metadata.AppendToOutgoingContext(withTenant("brand-b"), "x-api-key", "omk_1234_secret")
+	_, err = client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderId: "ORD-1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Streams are authenticated too
+	stream, err := client.WatchOrder(withTenant("brand-a"), &ordersv1.WatchOrderRequest{OrderId: "ORD-1"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
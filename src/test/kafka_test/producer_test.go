@@ -0,0 +1,44 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	producer "order-management-ms/src/main/pkg/kafka"
+	"order-management-ms/src/main/pkg/requestid"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// recordingWriter keeps the messages written to it
+type recordingWriter struct {
+	messages []kafka.Message
+}
+
+func (w *recordingWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *recordingWriter) Close() error { return nil }
+
+func TestPublishOrderStatusChangedCarriesRequestMetadata(t *testing.T) {
+	writer := &recordingWriter{}
+	p := producer.NewProducerWithWriter(writer, "order_events", zap.NewNop())
+
+	ctx := requestid.NewContext(tenant.NewContext(context.Background(), "brand-a"), "req-1")
+	event := kafkaDto.NewOrderStatusChangedEvent("ORD-1", "customer-1", dm.StatusNew, dm.StatusInProgress)
+
+	err := p.PublishOrderStatusChanged(ctx, event)
+
+	assert.NoError(t, err)
+	assert.Len(t, writer.messages, 1)
+	assert.Equal(t, "ORD-1", string(writer.messages[0].Key))
+	assert.Contains(t, writer.messages[0].Headers, kafka.Header{Key: producer.TenantHeader, Value: []byte("brand-a")})
+	assert.Contains(t, writer.messages[0].Headers, kafka.Header{Key: producer.RequestIDHeader, Value: []byte("req-1")})
+}
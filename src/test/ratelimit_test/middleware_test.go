@@ -0,0 +1,171 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	"order-management-ms/src/main/pkg/apikey"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/ratelimit"
+)
+
+var rateLimitConfig = config.RateLimit{
+	Enabled:   true,
+	Window:    time.Minute,
+	Timeout:   time.Second,
+	Limits:    map[string]int{"orders": 2},
+	Overrides: map[string]int{"1234/orders": 3},
+}
+
+// setupRouter serves a limited route, binding the API key given in the X-Key-ID header
+func setupRouter(t *testing.T, group string) (*gin.Engine, *miniredis.Miniredis) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	r := gin.New()
+	_ = r.SetTrustedProxies(nil)
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/orders",
+		func(c *gin.Context) {
+			if keyID := c.GetHeader("X-Key-ID"); keyID != "" {
+				c.Request = c.Request.WithContext(apikey.NewContext(c.Request.Context(), keyID))
+			}
+		},
+		ratelimit.Middleware(ratelimit.NewLimiter(client), rateLimitConfig, group, zap.NewNop()),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r, server
+}
+
+func get(r *gin.Engine, keyID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Accept", "application/json")
+	if keyID != "" {
+		req.Header.Set("X-Key-ID", keyID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareLimitsClients(t *testing.T) {
+	r, _ := setupRouter(t, "orders")
+
+	w := get(r, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("RateLimit-Reset"))
+
+	w = get(r, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = get(r, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, `{"code":"RATE_LIMITED","message":"too many requests"}`, w.Body.String())
+	assert.Equal(t, w.Header().Get("RateLimit-Reset"), w.Header().Get("Retry-After"))
+
+	// API keys are counted on their own, with their overrides
+	for i := 0; i < 3; i++ {
+		w = get(r, "1234")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, get(r, "1234").Code)
+	assert.Equal(t, http.StatusOK, get(r, "5678").Code)
+}
+
+func TestMiddlewareFailsOpen(t *testing.T) {
+	r, server := setupRouter(t, "orders")
+	server.Close()
+
+	for i := 0; i < 3; i++ {
+		w := get(r, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestMiddlewareWithoutLimit(t *testing.T) {
+	r, _ := setupRouter(t, "graphql")
+
+	for i := 0; i < 3; i++ {
+		w := get(r, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestFailedAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// authenticate accepts the "valid" token, counting the credentials it checks
+	var checked atomic.Int32
+	authenticate := func(c *gin.Context) {
+		checked.Add(1)
+		if c.GetHeader("Authorization") != "valid" {
+			_ = c.Error(customerrors.ErrInvalidAuthToken)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(authz.NewContext(c.Request.Context(), authz.NewAccess("", nil)))
+		c.Next()
+	}
+	cfg := rateLimitConfig
+	cfg.Limits = map[string]int{"auth-failures": 2}
+
+	r := gin.New()
+	_ = r.SetTrustedProxies(nil)
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/orders",
+		ratelimit.FailedAuthentication(ratelimit.NewLimiter(client), cfg, "auth-failures", authenticate, zap.NewNop()),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(authorization, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", authorization)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Successful authentications are not counted
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request("valid", "192.0.2.1").Code)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("omk_bogus", "192.0.2.1").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("omk_bogus", "192.0.2.1").Code)
+	require.Equal(t, int32(5), checked.Load())
+
+	// The IP is then rejected without its credentials being checked
+	w := request("omk_bogus", "192.0.2.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, request("valid", "192.0.2.1").Code)
+	assert.Equal(t, int32(5), checked.Load())
+
+	// Other clients are not affected
+	assert.Equal(t, http.StatusUnauthorized, request("omk_bogus", "192.0.2.2").Code)
+	assert.Equal(t, http.StatusOK, request("valid", "192.0.2.2").Code)
+}
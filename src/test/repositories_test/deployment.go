@@ -0,0 +1,31 @@
+package repositories_test
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.uber.org/zap"
+
+	repositories "order-management-ms/src/main/repositories/mongodb"
+)
+
+// newRepository returns an order repository on the mock deployment of the test, with the
+// orders and orders_archive collections
+func newRepository(mt *mtest.T) *repositories.OrderRepositoryMongoDB {
+	return repositories.NewOrderRepository(mt.DB, "orders", "orders_archive", zap.NewNop())
+}
+
+// commands returns the commands with the given name sent to the mock deployment, in order
+func commands(mt *mtest.T, name string) []bson.Raw {
+	var sent []bson.Raw
+	for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+		if event.CommandName == name {
+			sent = append(sent, event.Command)
+		}
+	}
+	return sent
+}
+
+// cursor is the response to a find or an aggregate on the orders returning the documents
+func cursor(documents ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "test.orders", mtest.FirstBatch, documents...)
+}
@@ -0,0 +1,95 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// updates returns the update statements sent to each collection
+func updates(mt *mtest.T) map[string][]bson.Raw {
+	sent := map[string][]bson.Raw{}
+	for _, command := range commands(mt, "update") {
+		collection := command.Lookup("update").StringValue()
+		values, err := command.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		for _, value := range values {
+			sent[collection] = append(sent[collection], value.Document())
+		}
+	}
+	return sent
+}
+
+func TestMigrateBackfillsTenant(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("assigns the orders without tenant to the default tenant", func(mt *mtest.T) {
+		repo := newRepository(mt)
+		for i := 0; i < 4; i++ {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		}
+
+		require.NoError(mt, repo.Migrate(context.Background(), "brand-default"))
+
+		sent := updates(mt)
+		for _, collection := range []string{"orders", "orders_archive"} {
+			require.Len(mt, sent[collection], 2, collection)
+			statement := sent[collection][0]
+			assert.Equal(mt, bson.TypeNull, statement.Lookup("q", "tenant_id").Type, collection)
+			assert.Equal(mt, "brand-default", statement.Lookup("u", "$set", "tenant_id").StringValue(), collection)
+			assert.True(mt, statement.Lookup("multi").Boolean(), collection)
+		}
+	})
+
+	mt.Run("leaves them unassigned without a default tenant", func(mt *mtest.T) {
+		repo := newRepository(mt)
+		mt.AddMockResponses(
+			cursor(bson.D{{Key: "n", Value: 2}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			cursor(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+		)
+
+		require.NoError(mt, repo.Migrate(context.Background(), ""))
+
+		// Only the totals are backfilled
+		sent := updates(mt)
+		for _, collection := range []string{"orders", "orders_archive"} {
+			require.Len(mt, sent[collection], 1, collection)
+			assert.Equal(mt, bson.TypeBoolean, sent[collection][0].Lookup("q", "total", "$exists").Type, collection)
+		}
+	})
+}
+
+func TestMigrateBackfillsTotal(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("computes the total of the orders without one", func(mt *mtest.T) {
+		for i := 0; i < 4; i++ {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		}
+
+		require.NoError(mt, newRepository(mt).Migrate(context.Background(), "brand-default"))
+
+		sent := updates(mt)
+		for _, collection := range []string{"orders", "orders_archive"} {
+			require.Len(mt, sent[collection], 2, collection)
+			statement := sent[collection][1]
+			assert.False(mt, statement.Lookup("q", "total", "$exists").Boolean(), collection)
+
+			// The update is a pipeline rounding the sum of the items to cents
+			stages, err := statement.Lookup("u").Array().Values()
+			require.NoError(mt, err)
+			require.Len(mt, stages, 1)
+			round, err := stages[0].Document().Lookup("$set", "total", "$round").Array().Values()
+			require.NoError(mt, err)
+			require.Len(mt, round, 2)
+			assert.Equal(mt, int32(2), round[1].Int32())
+			assert.Equal(mt, "$items", round[0].Document().Lookup("$sum", "$map", "input", "$ifNull").Array().Index(0).Value().StringValue())
+		}
+	})
+}
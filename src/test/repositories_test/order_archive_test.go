@@ -0,0 +1,78 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+)
+
+func TestArchiveBefore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	finished := []dm.OrderStatus{dm.StatusDelivered, dm.StatusCancelled}
+
+	mt.Run("keeps the orders the archive rejected", func(mt *mtest.T) {
+		archived, rejected := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(
+			cursor(
+				bson.D{{Key: "_id", Value: archived}, {Key: "tenant_id", Value: "brand-a"}, {Key: "order_id", Value: "ORD-1"}},
+				bson.D{{Key: "_id", Value: rejected}, {Key: "tenant_id", Value: "brand-b"}, {Key: "order_id", Value: "ORD-1"}},
+			),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}),
+			cursor(bson.D{{Key: "_id", Value: archived}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		count, err := newRepository(mt).ArchiveBefore(context.Background(), finished, time.Now(), 100)
+		require.NoError(mt, err)
+		assert.Equal(mt, 1, count)
+
+		deletes := commands(mt, "delete")
+		require.Len(mt, deletes, 1)
+		values, err := deletes[0].Lookup("deletes").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, values, 1)
+		var query bson.M
+		require.NoError(mt, values[0].Document().Lookup("q").Unmarshal(&query))
+		assert.Equal(mt, bson.M{"_id": bson.M{"$in": bson.A{archived}}}, query)
+	})
+
+	mt.Run("removes nothing when no order reached the archive", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			cursor(bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "order_id", Value: "ORD-1"}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+			cursor(),
+		)
+
+		count, err := newRepository(mt).ArchiveBefore(context.Background(), finished, time.Now(), 100)
+		require.NoError(mt, err)
+		assert.Zero(mt, count)
+		assert.Empty(mt, commands(mt, "delete"))
+	})
+}
+
+func TestEnsureIndexesDropsTheUniqueArchiveOrderID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("archive order IDs are not unique", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 27, Name: "IndexNotFound", Message: "index not found with name [order_id_1]"}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		require.NoError(mt, newRepository(mt).EnsureIndexes(context.Background()))
+
+		drops := commands(mt, "dropIndexes")
+		require.Len(mt, drops, 1)
+		assert.Equal(mt, "orders_archive", drops[0].Lookup("dropIndexes").StringValue())
+		assert.Equal(mt, "order_id_1", drops[0].Lookup("index").StringValue())
+	})
+}
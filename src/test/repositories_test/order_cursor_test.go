@@ -0,0 +1,148 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// cursorOrders are stored orders, newest first. The second and third ones were created at the
+// same time, so only their _id orders them.
+var cursorOrders = func() []*dm.Order {
+	start := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	createdAt := []time.Time{start.Add(3 * time.Minute), start.Add(2 * time.Minute), start.Add(2 * time.Minute), start.Add(time.Minute), start}
+	orders := make([]*dm.Order, len(createdAt))
+	for i, t := range createdAt {
+		id := primitive.NewObjectIDFromTimestamp(start)
+		id[11] = byte(len(createdAt) - i)
+		orders[i] = &dm.Order{ID: id, OrderID: "ORD-" + string(rune('A'+i)), CreatedAt: t}
+	}
+	return orders
+}()
+
+// documents returns the stored documents of the orders, as the mock deployment answers them
+func documents(orders ...*dm.Order) []bson.D {
+	docs := make([]bson.D, len(orders))
+	for i, order := range orders {
+		docs[i] = bson.D{{Key: "_id", Value: order.ID}, {Key: "order_id", Value: order.OrderID}, {Key: "created_at", Value: order.CreatedAt}}
+	}
+	return docs
+}
+
+func orderIDs(orders []*dm.Order) []string {
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		ids[i] = order.OrderID
+	}
+	return ids
+}
+
+// decode decodes a value of a command
+func decode(mt *mtest.T, value bson.RawValue) bson.M {
+	var decoded bson.M
+	require.NoError(mt, value.Unmarshal(&decoded))
+	return decoded
+}
+
+// positionFilter is the filter of the orders after (op $lt) or before (op $gt) the order in the
+// (created_at, _id) ordering
+func positionFilter(order *dm.Order, op string) bson.M {
+	createdAt := primitive.NewDateTimeFromTime(order.CreatedAt)
+	return bson.M{"$and": bson.A{
+		bson.M{"tenant_id": "brand-a"},
+		bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{op: createdAt}},
+			bson.M{"created_at": createdAt, "_id": bson.M{op: order.ID}},
+		}},
+	}}
+}
+
+func TestListByCursor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+	newestFirst := bson.M{"created_at": int32(-1), "_id": int32(-1)}
+	oldestFirst := bson.M{"created_at": int32(1), "_id": int32(1)}
+
+	list := func(mt *mtest.T, from *dm.Cursor, answer ...*dm.Order) (*dm.OrderPage, bson.Raw) {
+		mt.AddMockResponses(cursor(documents(answer...)...))
+		page, err := newRepository(mt).List(ctx, dm.OrderFilter{}, dm.ListOptions{CursorMode: true, Cursor: from, Limit: 2})
+		require.NoError(mt, err)
+		finds := commands(mt, "find")
+		require.Len(mt, finds, 1)
+		return page, finds[0]
+	}
+
+	mt.Run("first page", func(mt *mtest.T) {
+		// One order more than the limit is read to know there is a next page
+		page, find := list(mt, nil, cursorOrders[0:3]...)
+
+		assert.Equal(mt, int64(3), find.Lookup("limit").Int64())
+		assert.Equal(mt, newestFirst, decode(mt, find.Lookup("sort")))
+		assert.Equal(mt, bson.M{"tenant_id": "brand-a"}, decode(mt, find.Lookup("filter")))
+
+		assert.Equal(mt, []string{"ORD-A", "ORD-B"}, orderIDs(page.Orders))
+		assert.True(mt, page.HasMore)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[1], false), page.NextCursor)
+		assert.Nil(mt, page.PrevCursor)
+	})
+
+	mt.Run("next page breaks created_at ties by _id", func(mt *mtest.T) {
+		page, find := list(mt, dm.NewCursor(cursorOrders[1], false), cursorOrders[2:5]...)
+
+		assert.Equal(mt, newestFirst, decode(mt, find.Lookup("sort")))
+		assert.Equal(mt, positionFilter(cursorOrders[1], "$lt"), decode(mt, find.Lookup("filter")))
+
+		assert.Equal(mt, []string{"ORD-C", "ORD-D"}, orderIDs(page.Orders))
+		assert.True(mt, page.HasMore)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[3], false), page.NextCursor)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[2], true), page.PrevCursor)
+	})
+
+	mt.Run("last page", func(mt *mtest.T) {
+		page, _ := list(mt, dm.NewCursor(cursorOrders[3], false), cursorOrders[4])
+
+		assert.Equal(mt, []string{"ORD-E"}, orderIDs(page.Orders))
+		assert.False(mt, page.HasMore)
+		assert.Nil(mt, page.NextCursor)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[4], true), page.PrevCursor)
+	})
+
+	mt.Run("previous page is read oldest first and reversed", func(mt *mtest.T) {
+		page, find := list(mt, dm.NewCursor(cursorOrders[3], true), cursorOrders[2], cursorOrders[1], cursorOrders[0])
+
+		assert.Equal(mt, int64(3), find.Lookup("limit").Int64())
+		assert.Equal(mt, oldestFirst, decode(mt, find.Lookup("sort")))
+		assert.Equal(mt, positionFilter(cursorOrders[3], "$gt"), decode(mt, find.Lookup("filter")))
+
+		assert.Equal(mt, []string{"ORD-B", "ORD-C"}, orderIDs(page.Orders))
+		assert.True(mt, page.HasMore)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[2], false), page.NextCursor)
+		assert.Equal(mt, dm.NewCursor(cursorOrders[1], true), page.PrevCursor)
+	})
+
+	mt.Run("previous page reaching the newest order", func(mt *mtest.T) {
+		page, _ := list(mt, dm.NewCursor(cursorOrders[2], true), cursorOrders[1], cursorOrders[0])
+
+		assert.Equal(mt, []string{"ORD-A", "ORD-B"}, orderIDs(page.Orders))
+		assert.Equal(mt, dm.NewCursor(cursorOrders[1], false), page.NextCursor)
+		assert.Nil(mt, page.PrevCursor)
+	})
+
+	mt.Run("empty page", func(mt *mtest.T) {
+		page, _ := list(mt, dm.NewCursor(cursorOrders[4], false))
+
+		assert.Empty(mt, page.Orders)
+		assert.False(mt, page.HasMore)
+		assert.Nil(mt, page.NextCursor)
+		assert.Nil(mt, page.PrevCursor)
+	})
+}
@@ -0,0 +1,58 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+func TestExportReadsTheArchiveOnRequest(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+
+	export := func(mt *mtest.T, filter dm.OrderFilter) []string {
+		var exported []string
+		err := newRepository(mt).Export(ctx, filter, nil, dm.ReadOptions{}, func(order *dm.Order) error {
+			exported = append(exported, order.OrderID)
+			return nil
+		})
+		require.NoError(mt, err)
+		return exported
+	}
+
+	mt.Run("live orders only by default", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(bson.D{{Key: "order_id", Value: "ORD-1"}}))
+
+		assert.Equal(mt, []string{"ORD-1"}, export(mt, dm.OrderFilter{}))
+
+		finds := commands(mt, "find")
+		require.Len(mt, finds, 1)
+		assert.Equal(mt, "orders", finds[0].Lookup("find").StringValue())
+	})
+
+	mt.Run("archived orders after the live ones", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			cursor(bson.D{{Key: "order_id", Value: "ORD-2"}}),
+			cursor(bson.D{{Key: "order_id", Value: "ORD-1"}}),
+		)
+
+		assert.Equal(mt, []string{"ORD-2", "ORD-1"}, export(mt, dm.OrderFilter{IncludeArchived: true}))
+
+		finds := commands(mt, "find")
+		require.Len(mt, finds, 2)
+		assert.Equal(mt, "orders", finds[0].Lookup("find").StringValue())
+		assert.Equal(mt, "orders_archive", finds[1].Lookup("find").StringValue())
+		for _, find := range finds {
+			assert.Equal(mt, "brand-a", find.Lookup("filter", "tenant_id").StringValue())
+			assert.Equal(mt, int32(-1), find.Lookup("sort", "created_at").Int32())
+		}
+		assert.True(mt, finds[1].Lookup("allowDiskUse").Boolean())
+	})
+}
@@ -0,0 +1,73 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+func TestReadProjection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+
+	// sparse is the projection of the fields, with the tenant and the cursor keys read on every read
+	sparse := func(fields ...string) bson.M {
+		projection := bson.M{"tenant_id": int32(1), "created_at": int32(1)}
+		for _, field := range fields {
+			projection[field] = int32(1)
+		}
+		return projection
+	}
+
+	tests := []struct {
+		name string
+		read dm.ReadOptions
+		want bson.M
+	}{
+		{
+			name: "every field but the history by default",
+			want: bson.M{"status_history": int32(0)},
+		},
+		{
+			name: "every field with the history expanded",
+			read: dm.ReadOptions{Expand: []string{dm.ExpandHistory}},
+			want: bson.M{},
+		},
+		{
+			name: "fieldset",
+			read: dm.ReadOptions{Fields: []string{"order_id", "status"}},
+			want: sparse("order_id", "status"),
+		},
+		{
+			name: "fieldset with the history expanded",
+			read: dm.ReadOptions{Fields: []string{"order_id"}, Expand: []string{dm.ExpandHistory}},
+			want: sparse("order_id", "status_history"),
+		},
+		{
+			// The service adds the owner and the fields of the ETag to the fieldsets
+			name: "fieldset with the owner and the version",
+			read: dm.ReadOptions{Fields: []string{"status", "customer_id", "updated_at", "archived_at"}},
+			want: sparse("status", "customer_id", "updated_at", "archived_at"),
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(cursor())
+
+			_, err := newRepository(mt).List(ctx, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 10, Read: tt.read})
+			require.NoError(mt, err)
+
+			finds := commands(mt, "find")
+			require.Len(mt, finds, 1)
+			assert.Equal(mt, tt.want, decode(mt, finds[0].Lookup("projection")))
+		})
+	}
+}
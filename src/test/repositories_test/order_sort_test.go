@@ -0,0 +1,119 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+func TestListSort(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+
+	asc := func(field string) dm.SortField { return dm.SortField{Field: field} }
+	desc := func(field string) dm.SortField { return dm.SortField{Field: field, Descending: true} }
+
+	tests := []struct {
+		name       string
+		sort       []dm.SortField
+		cursorMode bool
+		want       bson.D
+		wantErr    error
+	}{
+		{
+			name: "newest first by default",
+			want: bson.D{{Key: "created_at", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}},
+		},
+		{
+			name: "oldest first",
+			sort: []dm.SortField{asc(dm.SortByCreatedAt)},
+			want: bson.D{{Key: "created_at", Value: int32(1)}, {Key: "_id", Value: int32(1)}},
+		},
+		{
+			name: "recently updated first",
+			sort: []dm.SortField{desc(dm.SortByUpdatedAt)},
+			want: bson.D{{Key: "updated_at", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}},
+		},
+		{
+			name: "status completed by the rest of its index",
+			sort: []dm.SortField{asc(dm.SortByStatus)},
+			want: bson.D{{Key: "status", Value: int32(1)}, {Key: "created_at", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}},
+		},
+		{
+			name: "status walked backwards",
+			sort: []dm.SortField{desc(dm.SortByStatus)},
+			want: bson.D{{Key: "status", Value: int32(-1)}, {Key: "created_at", Value: int32(1)}, {Key: "_id", Value: int32(1)}},
+		},
+		{
+			name: "customer then newest",
+			sort: []dm.SortField{asc(dm.SortByCustomerID), desc(dm.SortByCreatedAt)},
+			want: bson.D{{Key: "customer_id", Value: int32(1)}, {Key: "created_at", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}},
+		},
+		{
+			name: "customer walked backwards",
+			sort: []dm.SortField{desc(dm.SortByCustomerID), asc(dm.SortByCreatedAt)},
+			want: bson.D{{Key: "customer_id", Value: int32(-1)}, {Key: "created_at", Value: int32(1)}, {Key: "_id", Value: int32(1)}},
+		},
+		{
+			name: "smallest total first",
+			sort: []dm.SortField{asc(dm.SortByTotal)},
+			want: bson.D{{Key: "total", Value: int32(1)}, {Key: "_id", Value: int32(1)}},
+		},
+		{
+			name:    "mixed directions no index follows",
+			sort:    []dm.SortField{asc(dm.SortByStatus), asc(dm.SortByCreatedAt)},
+			wantErr: customerrors.ErrUnsupportedSort,
+		},
+		{
+			name:    "keys in an order no index has",
+			sort:    []dm.SortField{desc(dm.SortByCreatedAt), asc(dm.SortByStatus)},
+			wantErr: customerrors.ErrUnsupportedSort,
+		},
+		{
+			name:    "keys of different indexes",
+			sort:    []dm.SortField{desc(dm.SortByTotal), desc(dm.SortByCreatedAt)},
+			wantErr: customerrors.ErrUnsupportedSort,
+		},
+		{
+			name:       "newest first by cursor",
+			sort:       []dm.SortField{desc(dm.SortByCreatedAt)},
+			cursorMode: true,
+			want:       bson.D{{Key: "created_at", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}},
+		},
+		{
+			name:       "any other sort by cursor",
+			sort:       []dm.SortField{asc(dm.SortByCreatedAt)},
+			cursorMode: true,
+			wantErr:    customerrors.ErrCursorSort,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(cursor())
+
+			_, err := newRepository(mt).List(ctx, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 10, CursorMode: tt.cursorMode, Sort: tt.sort})
+
+			finds := commands(mt, "find")
+			if tt.wantErr != nil {
+				assert.ErrorIs(mt, err, tt.wantErr)
+				assert.Empty(mt, finds)
+				return
+			}
+			require.NoError(mt, err)
+			require.Len(mt, finds, 1)
+
+			var sort bson.D
+			require.NoError(mt, finds[0].Lookup("sort").Unmarshal(&sort))
+			assert.Equal(mt, tt.want, sort)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+func TestUpdateStatusIsConditional(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+
+	mt.Run("updates the order still in the status read", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		require.NoError(mt, newRepository(mt).UpdateStatus(ctx, "ORD-1", dm.StatusInProgress, dm.StatusCancelled))
+
+		statements := updates(mt)["orders"]
+		require.Len(mt, statements, 1)
+		assert.Equal(mt, "ORD-1", statements[0].Lookup("q", "order_id").StringValue())
+		assert.Equal(mt, string(dm.StatusInProgress), statements[0].Lookup("q", "status").StringValue())
+		assert.Equal(mt, string(dm.StatusCancelled), statements[0].Lookup("u", "$set", "status").StringValue())
+	})
+
+	mt.Run("rejects the order changed since it was read", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := newRepository(mt).UpdateStatus(ctx, "ORD-1", dm.StatusInProgress, dm.StatusCancelled)
+		assert.ErrorIs(mt, err, customerrors.ErrInvalidTransition)
+	})
+}
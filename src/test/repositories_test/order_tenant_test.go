@@ -0,0 +1,76 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+func TestQueriesAreScopedToTheTenant(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := tenant.NewContext(context.Background(), "brand-b")
+
+	mt.Run("reads by ID, in the live and the archive collections", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(), cursor())
+
+		_, err := newRepository(mt).FindByID(ctx, "ORD-1", dm.ReadOptions{})
+		assert.ErrorIs(mt, err, customerrors.ErrOrderNotFound)
+
+		finds := commands(mt, "find")
+		require.Len(mt, finds, 2)
+		for i, collection := range []string{"orders", "orders_archive"} {
+			assert.Equal(mt, collection, finds[i].Lookup("find").StringValue())
+			assert.Equal(mt, "brand-b", finds[i].Lookup("filter", "tenant_id").StringValue())
+			assert.Equal(mt, "ORD-1", finds[i].Lookup("filter", "order_id").StringValue())
+		}
+	})
+
+	mt.Run("listings", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor())
+
+		_, err := newRepository(mt).List(ctx, dm.OrderFilter{CustomerID: "customer-1"}, dm.ListOptions{Page: 1, Limit: 10})
+		require.NoError(mt, err)
+
+		finds := commands(mt, "find")
+		require.Len(mt, finds, 1)
+		assert.Equal(mt, "brand-b", finds[0].Lookup("filter", "tenant_id").StringValue())
+		assert.Equal(mt, "customer-1", finds[0].Lookup("filter", "customer_id").StringValue())
+	})
+
+	mt.Run("status updates", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := newRepository(mt).UpdateStatus(ctx, "ORD-1", dm.StatusNew, dm.StatusInProgress)
+		assert.ErrorIs(mt, err, customerrors.ErrInvalidTransition)
+
+		statements := updates(mt)["orders"]
+		require.Len(mt, statements, 1)
+		assert.Equal(mt, "brand-b", statements[0].Lookup("q", "tenant_id").StringValue())
+	})
+
+	mt.Run("nothing is queried without a tenant", func(mt *mtest.T) {
+		repo := newRepository(mt)
+		noTenant := context.Background()
+
+		_, err := repo.FindByID(noTenant, "ORD-1", dm.ReadOptions{})
+		assert.ErrorIs(mt, err, customerrors.ErrMissingTenant)
+		_, err = repo.List(noTenant, dm.OrderFilter{}, dm.ListOptions{Page: 1, Limit: 10})
+		assert.ErrorIs(mt, err, customerrors.ErrMissingTenant)
+		_, err = repo.Count(noTenant, dm.OrderFilter{})
+		assert.ErrorIs(mt, err, customerrors.ErrMissingTenant)
+		err = repo.UpdateStatus(noTenant, "ORD-1", dm.StatusNew, dm.StatusInProgress)
+		assert.ErrorIs(mt, err, customerrors.ErrMissingTenant)
+		err = repo.Export(noTenant, dm.OrderFilter{}, nil, dm.ReadOptions{}, func(*dm.Order) error { return nil })
+		assert.ErrorIs(mt, err, customerrors.ErrMissingTenant)
+
+		assert.Nil(mt, mt.GetStartedEvent())
+	})
+}
@@ -0,0 +1,70 @@
+package requestid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"order-management-ms/src/main/pkg/requestid"
+)
+
+// setupRouter serves a route returning the request ID bound to the context
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestid.Middleware())
+	r.GET("/orders", func(c *gin.Context) {
+		requestID, _ := requestid.FromContext(c.Request.Context())
+		c.String(http.StatusOK, requestID)
+	})
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	r := setupRouter()
+
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{name: "keeps the request ID of the client", requestID: "3f2a9c0d-1e4b-4a67-9c1d-0e5f6a7b8c9d", keep: true},
+		{name: "generates a request ID when none is sent"},
+		{name: "replaces an invalid request ID", requestID: "bad id\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set(requestid.Header, tt.requestID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(requestid.Header)
+			assert.Equal(t, echoed, w.Body.String())
+			if tt.keep {
+				assert.Equal(t, tt.requestID, echoed)
+			} else {
+				assert.Regexp(t, `^[0-9a-f-]{36}$`, echoed)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	requestid.Logger(requestid.NewContext(context.Background(), "req-1"), logger).Info("with")
+	requestid.Logger(context.Background(), logger).Info("without")
+
+	entries := logs.All()
+	assert.Equal(t, map[string]interface{}{"request_id": "req-1"}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+}
@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+	"order-management-ms/src/main/services/apikeys"
+)
+
+// fakeAPIKeyRepository keeps API keys in memory, counting the recorded uses
+type fakeAPIKeyRepository struct {
+	keys    map[string]*dm.APIKey
+	touches int
+}
+
+func newFakeAPIKeyRepository() *fakeAPIKeyRepository {
+	return &fakeAPIKeyRepository{keys: map[string]*dm.APIKey{}}
+}
+
+func (f *fakeAPIKeyRepository) CreateKey(ctx context.Context, key *dm.APIKey) error {
+	key.TenantID, _ = tenant.FromContext(ctx)
+	stored := *key
+	f.keys[key.KeyID] = &stored
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) ListKeys(ctx context.Context) ([]*dm.APIKey, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	keys := []*dm.APIKey{}
+	for _, key := range f.keys {
+		if key.TenantID == tenantID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeAPIKeyRepository) FindKey(ctx context.Context, keyID string) (*dm.APIKey, error) {
+	key, ok := f.keys[keyID]
+	if !ok {
+		return nil, customerrors.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (f *fakeAPIKeyRepository) RotateKey(ctx context.Context, keyID, hash string, previousExpiresAt time.Time) (*dm.APIKey, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	key, ok := f.keys[keyID]
+	if !ok || key.TenantID != tenantID || key.RevokedAt != nil {
+		return nil, customerrors.ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	key.PreviousHash, key.PreviousExpiresAt, key.Hash, key.RotatedAt = key.Hash, &previousExpiresAt, hash, &now
+	return key, nil
+}
+
+func (f *fakeAPIKeyRepository) RevokeKey(ctx context.Context, keyID string) error {
+	tenantID, _ := tenant.FromContext(ctx)
+	key, ok := f.keys[keyID]
+	if !ok || key.TenantID != tenantID {
+		return customerrors.ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) TouchKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	f.touches++
+	f.keys[keyID].LastUsedAt = &usedAt
+	return nil
+}
+
+var apiKeysConfig = config.APIKeys{RotationGrace: time.Hour, LastUsedInterval: time.Minute}
+
+// admin returns a context of the brand-a tenant for an admin of the default policy
+func admin() context.Context {
+	return as("staff-1", "admin")
+}
+
+func TestIssueAndAuthenticateAPIKey(t *testing.T) {
+	repo := newFakeAPIKeyRepository()
+	svc := apikeys.NewAPIKeyService(repo, apiKeysConfig, zap.NewNop())
+
+	issued, err := svc.IssueKey(admin(), &dm.APIKey{Name: "Partner", Scopes: []string{"orders:write"}})
+	require.NoError(t, err)
+	assert.Regexp(t, `^omk_[0-9a-f]{16}_[A-Za-z0-9_-]{43}$`, issued.Key)
+	assert.NotContains(t, repo.keys[issued.KeyID].Hash, issued.Key)
+
+	t.Run("authenticates the key and records its use", func(t *testing.T) {
+		key, err := svc.Authenticate(context.Background(), issued.Key, "198.51.100.7")
+		require.NoError(t, err)
+		assert.Equal(t, "brand-a", key.TenantID)
+		assert.Equal(t, []authz.Permission{authz.WriteOrders}, apikeys.Permissions(key))
+		assert.NotNil(t, repo.keys[issued.KeyID].LastUsedAt)
+
+		// Uses within the interval are not recorded again
+		_, err = svc.Authenticate(context.Background(), issued.Key, "198.51.100.7")
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.touches)
+	})
+
+	t.Run("rejects unknown and tampered keys", func(t *testing.T) {
+		for _, plainKey := range []string{"", "omk_", "not-a-key", "omk_0000000000000000_secret", issued.Key + "x"} {
+			_, err := svc.Authenticate(context.Background(), plainKey, "198.51.100.7")
+			assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey, plainKey)
+		}
+	})
+
+	t.Run("lists keys without their secrets", func(t *testing.T) {
+		keys, err := svc.ListKeys(admin())
+		require.NoError(t, err)
+		require.Len(t, keys.Data, 1)
+		assert.Empty(t, keys.Data[0].Key)
+		assert.Equal(t, "Partner", keys.Data[0].Name)
+	})
+}
+
+func TestAPIKeyAllowList(t *testing.T) {
+	svc := apikeys.NewAPIKeyService(newFakeAPIKeyRepository(), apiKeysConfig, zap.NewNop())
+	issued, err := svc.IssueKey(admin(), &dm.APIKey{
+		Name:       "Partner",
+		Scopes:     []string{"orders:read"},
+		AllowedIPs: []string{"203.0.113.0/24", "2001:db8::1"},
+	})
+	require.NoError(t, err)
+
+	for ip, allowed := range map[string]bool{
+		"203.0.113.10":        true,
+		"::ffff:203.0.113.10": true,
+		"2001:db8::1":         true,
+		"203.0.114.10":        false,
+		"2001:db8::2":         false,
+		"":                    false,
+	} {
+		_, err := svc.Authenticate(context.Background(), issued.Key, ip)
+		if allowed {
+			assert.NoError(t, err, ip)
+		} else {
+			assert.ErrorIs(t, err, customerrors.ErrIPNotAllowed, ip)
+		}
+	}
+}
+
+func TestRotateAndRevokeAPIKey(t *testing.T) {
+	repo := newFakeAPIKeyRepository()
+	svc := apikeys.NewAPIKeyService(repo, apiKeysConfig, zap.NewNop())
+	issued, err := svc.IssueKey(admin(), &dm.APIKey{Name: "Partner", Scopes: []string{"orders:read"}})
+	require.NoError(t, err)
+
+	rotated, err := svc.RotateKey(admin(), issued.KeyID)
+	require.NoError(t, err)
+	assert.Equal(t, issued.KeyID, rotated.KeyID)
+	assert.NotEqual(t, issued.Key, rotated.Key)
+	assert.NotNil(t, rotated.RotatedAt)
+
+	// Both keys work during the grace period
+	_, err = svc.Authenticate(context.Background(), rotated.Key, "198.51.100.7")
+	assert.NoError(t, err)
+	_, err = svc.Authenticate(context.Background(), issued.Key, "198.51.100.7")
+	assert.NoError(t, err)
+
+	// The previous key stops working once the grace period is over
+	expired := time.Now().Add(-time.Second)
+	repo.keys[issued.KeyID].PreviousExpiresAt = &expired
+	_, err = svc.Authenticate(context.Background(), issued.Key, "198.51.100.7")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey)
+
+	// Keys of other tenants cannot be managed
+	otherTenant := authz.NewContext(tenant.NewContext(context.Background(), "brand-b"),
+		authz.DefaultPolicy().Access("staff-2", []string{"admin"}))
+	assert.ErrorIs(t, svc.RevokeKey(otherTenant, issued.KeyID), customerrors.ErrAPIKeyNotFound)
+
+	require.NoError(t, svc.RevokeKey(admin(), issued.KeyID))
+	_, err = svc.Authenticate(context.Background(), rotated.Key, "198.51.100.7")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey)
+
+	_, err = svc.RotateKey(admin(), issued.KeyID)
+	assert.ErrorIs(t, err, customerrors.ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyManagementRequiresPermission(t *testing.T) {
+	svc := apikeys.NewAPIKeyService(newFakeAPIKeyRepository(), apiKeysConfig, zap.NewNop())
+	operator := as("staff-1", "operator")
+
+	_, err := svc.IssueKey(operator, &dm.APIKey{Name: "Partner", Scopes: []string{"orders:read"}})
+	assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	_, err = svc.ListKeys(operator)
+	assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	_, err = svc.RotateKey(operator, "0000000000000000")
+	assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	assert.ErrorIs(t, svc.RevokeKey(operator, "0000000000000000"), customerrors.ErrForbidden)
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"order-management-ms/src/main/config"
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/services/archive"
+)
+
+// fakeArchiveRepository archives a fixed number of orders in batches
+type fakeArchiveRepository struct {
+	remaining int
+	calls     int
+	statuses  []dm.OrderStatus
+	cutoff    time.Time
+	err       error
+}
+
+func (f *fakeArchiveRepository) ArchiveBefore(ctx context.Context, statuses []dm.OrderStatus, cutoff time.Time, batchSize int) (int, error) {
+	f.calls++
+	f.statuses = statuses
+	f.cutoff = cutoff
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	archived := batchSize
+	if f.remaining < batchSize {
+		archived = f.remaining
+	}
+	f.remaining -= archived
+	return archived, nil
+}
+
+func TestArchiveOrders(t *testing.T) {
+	cfg := config.Archive{Enabled: true, Retention: 24 * time.Hour, BatchSize: 10}
+
+	t.Run("archives in batches until a short batch", func(t *testing.T) {
+		repo := &fakeArchiveRepository{remaining: 25}
+		svc := archive.NewArchiveService(repo, cfg, zap.NewNop())
+
+		total, err := svc.ArchiveOrders(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 25, total)
+		assert.Equal(t, 3, repo.calls)
+		assert.ElementsMatch(t, []dm.OrderStatus{dm.StatusDelivered, dm.StatusCancelled}, repo.statuses)
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), repo.cutoff, time.Minute)
+	})
+
+	t.Run("stops on repository errors", func(t *testing.T) {
+		repo := &fakeArchiveRepository{err: errors.New("boom")}
+		svc := archive.NewArchiveService(repo, cfg, zap.NewNop())
+
+		total, err := svc.ArchiveOrders(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, total)
+		assert.Equal(t, 1, repo.calls)
+	})
+}
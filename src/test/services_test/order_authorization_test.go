@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+	"order-management-ms/src/main/repositories"
+	"order-management-ms/src/main/services/orders"
+)
+
+// fakeOrderRepository keeps the orders of a tenant in memory, recording the last filter and
+// read options and counting the reads by ID
+type fakeOrderRepository struct {
+	repositories.OrderRepository
+
+	orders    map[string]*dm.Order
+	filter    dm.OrderFilter
+	read      dm.ReadOptions
+	customers []string
+	finds     int
+}
+
+func (f *fakeOrderRepository) Create(ctx context.Context, order *dm.Order) (*dm.Order, error) {
+	order.OrderID = "ORD-new"
+	return order, nil
+}
+
+func (f *fakeOrderRepository) FindByID(ctx context.Context, orderID string, read dm.ReadOptions) (*dm.Order, error) {
+	f.read = read
+	f.finds++
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, customerrors.ErrOrderNotFound
+	}
+	// A copy, like a read from the database, so changes are only stored through UpdateStatus
+	stored := *order
+	return &stored, nil
+}
+
+func (f *fakeOrderRepository) FindByIDs(ctx context.Context, orderIDs []string, read dm.ReadOptions) ([]*dm.Order, error) {
+	f.read = read
+	var found []*dm.Order
+	for _, id := range orderIDs {
+		if order, ok := f.orders[id]; ok {
+			found = append(found, order)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeOrderRepository) UpdateStatus(ctx context.Context, orderID string, from, to dm.OrderStatus) error {
+	if f.orders[orderID].Status != from {
+		return customerrors.ErrInvalidTransition
+	}
+	f.orders[orderID].Status = to
+	return nil
+}
+
+func (f *fakeOrderRepository) List(ctx context.Context, filter dm.OrderFilter, opts dm.ListOptions) (*dm.OrderPage, error) {
+	f.filter = filter
+	return &dm.OrderPage{}, nil
+}
+
+func (f *fakeOrderRepository) SummarizeCustomers(ctx context.Context, customerIDs []string) ([]*dm.CustomerSummary, error) {
+	f.customers = customerIDs
+	return nil, nil
+}
+
+// noopCache never finds anything
+type noopCache struct{}
+
+func (noopCache) Get(ctx context.Context, key string) (string, error) {
+	return "", errors.New("cache miss")
+}
+func (noopCache) Set(ctx context.Context, key, value string, ttl time.Duration) error { return nil }
+func (noopCache) Delete(ctx context.Context, key string) error                        { return nil }
+
+// noopPublisher drops the events
+type noopPublisher struct{}
+
+func (noopPublisher) PublishOrderStatusChanged(ctx context.Context, event kafkaDto.OrderStatusChangedEvent) error {
+	return nil
+}
+
+func newAuthorizationFixture() (*fakeOrderRepository, orders.Service) {
+	repo := &fakeOrderRepository{orders: map[string]*dm.Order{
+		"ORD-1": {OrderID: "ORD-1", TenantID: "brand-a", CustomerID: "customer-1", Status: dm.StatusNew},
+		"ORD-2": {OrderID: "ORD-2", TenantID: "brand-a", CustomerID: "customer-2", Status: dm.StatusInProgress},
+	}}
+	return repo, orders.NewOrderService(repo, nil, zap.NewNop(), noopCache{}, noopPublisher{})
+}
+
+// as returns a context of the brand-a tenant for a caller with the roles of the default policy
+func as(customerID string, roles ...string) context.Context {
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+	return authz.NewContext(ctx, authz.DefaultPolicy().Access(customerID, roles))
+}
+
+func TestOrderAuthorization(t *testing.T) {
+	customer := as("customer-1", "customer")
+	operator := as("staff-1", "operator")
+	fulfillment := as("staff-2", "fulfillment")
+
+	t.Run("customers only get their own orders", func(t *testing.T) {
+		_, svc := newAuthorizationFixture()
+
+		order, err := svc.GetOrder(customer, "ORD-1", dm.ReadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ORD-1", order.OrderID)
+
+		_, err = svc.GetOrder(customer, "ORD-2", dm.ReadOptions{})
+		assert.ErrorIs(t, err, customerrors.ErrOrderNotFound)
+	})
+
+	t.Run("sparse reads still check the customer", func(t *testing.T) {
+		repo, svc := newAuthorizationFixture()
+
+		_, err := svc.GetOrder(customer, "ORD-2", dm.ReadOptions{Fields: []string{"status"}})
+		assert.ErrorIs(t, err, customerrors.ErrOrderNotFound)
+		assert.Equal(t, []string{"status", "customer_id", "updated_at", "archived_at"}, repo.read.Fields)
+	})
+
+	t.Run("customer listings are forced to the caller", func(t *testing.T) {
+		repo, svc := newAuthorizationFixture()
+
+		_, err := svc.ListOrders(customer, dm.OrderFilter{CustomerID: "customer-2"}, dm.ListOptions{SkipTotal: true})
+		require.NoError(t, err)
+		assert.Equal(t, "customer-1", repo.filter.CustomerID)
+	})
+
+	t.Run("operators list across customers", func(t *testing.T) {
+		repo, svc := newAuthorizationFixture()
+
+		_, err := svc.ListOrders(operator, dm.OrderFilter{}, dm.ListOptions{SkipTotal: true})
+		require.NoError(t, err)
+		assert.Empty(t, repo.filter.CustomerID)
+
+		order, err := svc.GetOrder(operator, "ORD-2", dm.ReadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ORD-2", order.OrderID)
+	})
+
+	t.Run("batches leave out other customers' orders", func(t *testing.T) {
+		repo, svc := newAuthorizationFixture()
+
+		found, err := svc.GetOrdersByIDs(customer, []string{"ORD-1", "ORD-2"}, dm.ReadOptions{})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "ORD-1", found[0].OrderID)
+
+		_, err = svc.GetCustomerSummaries(customer, []string{"customer-1", "customer-2"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"customer-1"}, repo.customers)
+	})
+
+	t.Run("customers only order for themselves", func(t *testing.T) {
+		_, svc := newAuthorizationFixture()
+
+		_, err := svc.CreateOrder(customer, &dm.Order{CustomerID: "customer-1"})
+		assert.NoError(t, err)
+
+		_, err = svc.CreateOrder(customer, &dm.Order{CustomerID: "customer-2"})
+		assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	})
+
+	t.Run("customers cannot change statuses or search", func(t *testing.T) {
+		_, svc := newAuthorizationFixture()
+
+		err := svc.UpdateOrderStatus(customer, "ORD-1", dm.StatusCancelled)
+		assert.ErrorIs(t, err, customerrors.ErrForbidden)
+
+		_, err = svc.SearchOrders(customer, "ORD", 10)
+		assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	})
+
+	t.Run("only fulfillment delivers orders", func(t *testing.T) {
+		repo, svc := newAuthorizationFixture()
+
+		err := svc.UpdateOrderStatus(operator, "ORD-2", dm.StatusDelivered)
+		assert.ErrorIs(t, err, customerrors.ErrForbidden)
+		assert.Equal(t, dm.StatusInProgress, repo.orders["ORD-2"].Status)
+
+		err = svc.UpdateOrderStatus(fulfillment, "ORD-2", dm.StatusDelivered)
+		assert.NoError(t, err)
+		assert.Equal(t, dm.StatusDelivered, repo.orders["ORD-2"].Status)
+
+		assert.NoError(t, svc.UpdateOrderStatus(operator, "ORD-1", dm.StatusCancelled))
+	})
+
+	t.Run("callers without a known role are forbidden", func(t *testing.T) {
+		_, svc := newAuthorizationFixture()
+
+		_, err := svc.GetOrder(as("someone", "guest"), "ORD-1", dm.ReadOptions{})
+		assert.ErrorIs(t, err, customerrors.ErrForbidden)
+	})
+}
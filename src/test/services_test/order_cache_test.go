@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/services/orders"
+)
+
+// memoryCache keeps the cached values in memory, ignoring their TTL
+type memoryCache map[string]string
+
+func (m memoryCache) Get(ctx context.Context, key string) (string, error) {
+	value, ok := m[key]
+	if !ok {
+		return "", errors.New("cache miss")
+	}
+	return value, nil
+}
+func (m memoryCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m[key] = value
+	return nil
+}
+func (m memoryCache) Delete(ctx context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestGetOrderCachesFullReads(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 1, 12, 30, 15, 0, time.UTC)
+	repo := &fakeOrderRepository{orders: map[string]*dm.Order{
+		"ORD-1": {OrderID: "ORD-1", TenantID: "brand-a", CustomerID: "customer-1", Status: dm.StatusNew, UpdatedAt: updatedAt},
+	}}
+	cache := memoryCache{}
+	svc := orders.NewOrderService(repo, nil, zap.NewNop(), cache, noopPublisher{})
+	operator := as("staff-1", "operator")
+
+	first, err := svc.GetOrder(operator, "ORD-1", dm.ReadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, updatedAt, first.UpdatedAt)
+	assert.Contains(t, cache, "tenant:brand-a:order:ORD-1")
+
+	// The next full read, and so its ETag, is answered from the cache
+	cached, err := svc.GetOrder(operator, "ORD-1", dm.ReadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.finds)
+	assert.Equal(t, first.ETag(), cached.ETag())
+
+	// Other customers are still not found on cached reads
+	_, err = svc.GetOrder(as("customer-2", "customer"), "ORD-1", dm.ReadOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, repo.finds)
+
+	// Sparse reads go to the database and keep the version of the order
+	sparse, err := svc.GetOrder(operator, "ORD-1", dm.ReadOptions{Fields: []string{"status"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.finds)
+	assert.Equal(t, []string{"status", "updated_at", "archived_at"}, repo.read.Fields)
+	assert.Equal(t, first.ETag(), sparse.ETag())
+
+	// Updates invalidate the cached order
+	require.NoError(t, svc.UpdateOrderStatus(operator, "ORD-1", dm.StatusInProgress))
+	assert.NotContains(t, cache, "tenant:brand-a:order:ORD-1")
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/tenant"
+	memoryrepo "order-management-ms/src/main/repositories/memory"
+	"order-management-ms/src/main/services/orders"
+)
+
+func TestSearchOrders(t *testing.T) {
+	index := memoryrepo.NewOrderSearchIndex()
+	index.Index(&dm.Order{
+		TenantID:        "brand-a",
+		OrderID:         "ORD-e7825df7",
+		CustomerName:    "Ada Lovelace",
+		ShippingAddress: "12 Main Street",
+		Items:           []dm.OrderItem{{Sku: "JNS-CLS-32", Quantity: 1}},
+	})
+	index.Index(&dm.Order{
+		TenantID:        "brand-a",
+		OrderID:         "ORD-12b72b69",
+		CustomerName:    "Grace Hopper",
+		ShippingAddress: "1 Navy Yard <Main> Gate",
+		Items:           []dm.OrderItem{{Sku: "TSH-BLK-M", Quantity: 2}},
+	})
+	index.Index(&dm.Order{
+		TenantID:     "brand-b",
+		OrderID:      "ORD-e7825aaa",
+		CustomerName: "Ada Other",
+	})
+
+	svc := orders.NewOrderService(nil, index, zap.NewNop(), nil, nil)
+	ctx := tenant.NewContext(context.Background(), "brand-a")
+
+	t.Run("partial order ID", func(t *testing.T) {
+		results, err := svc.SearchOrders(ctx, "e7825", 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, results.Data, 1)
+		assert.Equal(t, "ORD-e7825df7", results.Data[0].Order.OrderID)
+		assert.Equal(t, []string{"ORD-<em>e7825</em>df7"}, results.Data[0].Highlights["order_id"])
+	})
+
+	t.Run("ranks by weighted field matches and escapes highlights", func(t *testing.T) {
+		results, err := svc.SearchOrders(ctx, "main ada", 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, results.Data, 2)
+		assert.Equal(t, "ORD-e7825df7", results.Data[0].Order.OrderID)
+		assert.Greater(t, results.Data[0].Score, results.Data[1].Score)
+		assert.Equal(t, []string{"1 Navy Yard &lt;<em>Main</em>&gt; Gate"}, results.Data[1].Highlights["shipping_address"])
+	})
+
+	t.Run("never returns other tenants' orders", func(t *testing.T) {
+		results, err := svc.SearchOrders(tenant.NewContext(context.Background(), "brand-b"), "e7825", 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, results.Data, 1)
+		assert.Equal(t, "ORD-e7825aaa", results.Data[0].Order.OrderID)
+	})
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	kafkaDto "order-management-ms/src/main/models/kafka"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/services/orders"
+)
+
+// racingOrderRepository changes the status of each order right after it is read, like a
+// concurrent request
+type racingOrderRepository struct {
+	*fakeOrderRepository
+	concurrent dm.OrderStatus
+}
+
+func (r *racingOrderRepository) FindByID(ctx context.Context, orderID string, read dm.ReadOptions) (*dm.Order, error) {
+	order, err := r.fakeOrderRepository.FindByID(ctx, orderID, read)
+	if err == nil {
+		r.orders[orderID].Status = r.concurrent
+	}
+	return order, err
+}
+
+// recordingPublisher keeps the published events
+type recordingPublisher struct {
+	events []kafkaDto.OrderStatusChangedEvent
+}
+
+func (p *recordingPublisher) PublishOrderStatusChanged(ctx context.Context, event kafkaDto.OrderStatusChangedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestUpdateOrderStatusLosesToConcurrentChanges(t *testing.T) {
+	fake, _ := newAuthorizationFixture()
+	repo := &racingOrderRepository{fakeOrderRepository: fake, concurrent: dm.StatusDelivered}
+	publisher := &recordingPublisher{}
+	svc := orders.NewOrderService(repo, nil, zap.NewNop(), noopCache{}, publisher)
+
+	// The order is delivered while it is being cancelled
+	err := svc.UpdateOrderStatus(as("staff-1", "operator"), "ORD-2", dm.StatusCancelled)
+
+	assert.ErrorIs(t, err, customerrors.ErrInvalidTransition)
+	assert.Equal(t, dm.StatusDelivered, fake.orders["ORD-2"].Status)
+	assert.Empty(t, publisher.events)
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	dm "order-management-ms/src/main/models/datastore"
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+	"order-management-ms/src/main/services/orders"
+)
+
+// tenantOrderRepository only finds the orders of the tenant in the context, like the
+// MongoDB repository
+type tenantOrderRepository struct {
+	*fakeOrderRepository
+}
+
+func (r tenantOrderRepository) FindByID(ctx context.Context, orderID string, read dm.ReadOptions) (*dm.Order, error) {
+	order, err := r.fakeOrderRepository.FindByID(ctx, orderID, read)
+	if tenantID, _ := tenant.FromContext(ctx); err == nil && order.TenantID != tenantID {
+		return nil, customerrors.ErrOrderNotFound
+	}
+	return order, err
+}
+
+// operatorOf returns a context of an operator of the tenant
+func operatorOf(tenantID string) context.Context {
+	ctx := tenant.NewContext(context.Background(), tenantID)
+	return authz.NewContext(ctx, authz.DefaultPolicy().Access("staff-1", []string{"operator"}))
+}
+
+func TestTenantsDoNotShareOrders(t *testing.T) {
+	repo := &fakeOrderRepository{orders: map[string]*dm.Order{
+		"ORD-1": {OrderID: "ORD-1", TenantID: "brand-a", CustomerID: "customer-1", Status: dm.StatusNew},
+	}}
+	cache := memoryCache{}
+	svc := orders.NewOrderService(tenantOrderRepository{repo}, nil, zap.NewNop(), cache, noopPublisher{})
+
+	order, err := svc.GetOrder(operatorOf("brand-a"), "ORD-1", dm.ReadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", order.OrderID)
+	require.Contains(t, cache, "tenant:brand-a:order:ORD-1")
+
+	t.Run("the cache entries of another tenant are not read", func(t *testing.T) {
+		_, err := svc.GetOrder(operatorOf("brand-b"), "ORD-1", dm.ReadOptions{})
+		assert.ErrorIs(t, err, customerrors.ErrOrderNotFound)
+		assert.NotContains(t, cache, "tenant:brand-b:order:ORD-1")
+	})
+
+	t.Run("a cached order of another tenant is not served", func(t *testing.T) {
+		stored, err := json.Marshal(repo.orders["ORD-1"])
+		require.NoError(t, err)
+		cache["tenant:brand-b:order:ORD-1"] = string(stored)
+		finds := repo.finds
+
+		_, err = svc.GetOrder(operatorOf("brand-b"), "ORD-1", dm.ReadOptions{})
+		assert.ErrorIs(t, err, customerrors.ErrOrderNotFound)
+		assert.Equal(t, finds+1, repo.finds)
+	})
+
+	t.Run("orders of another tenant are not updated", func(t *testing.T) {
+		delete(cache, "tenant:brand-b:order:ORD-1")
+
+		err := svc.UpdateOrderStatus(operatorOf("brand-b"), "ORD-1", dm.StatusInProgress)
+		assert.ErrorIs(t, err, customerrors.ErrOrderNotFound)
+		assert.Equal(t, dm.StatusNew, repo.orders["ORD-1"].Status)
+	})
+}
@@ -0,0 +1,125 @@
+package tenant_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"order-management-ms/src/main/pkg/authz"
+	"order-management-ms/src/main/pkg/customerrors"
+	"order-management-ms/src/main/pkg/tenant"
+)
+
+// setupRouter serves a route returning the tenant of the request. Callers are bound to the
+// tenant of the X-Bound-Tenant header, standing for their credentials, and granted the
+// permissions of the X-Permissions header.
+func setupRouter(defaultTenant string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(customerrors.ErrorHandler())
+	r.GET("/orders",
+		func(c *gin.Context) {
+			ctx := c.Request.Context()
+			if bound := c.GetHeader("X-Bound-Tenant"); bound != "" {
+				ctx = tenant.NewContext(ctx, bound)
+				ctx = authz.NewContext(ctx, authz.NewAccess("", []authz.Permission{authz.Permission(c.GetHeader("X-Permissions"))}))
+			}
+			c.Request = c.Request.WithContext(ctx)
+		},
+		tenant.Middleware("X-Tenant-ID", defaultTenant),
+		func(c *gin.Context) {
+			tenantID, _ := tenant.FromContext(c.Request.Context())
+			c.JSON(http.StatusOK, gin.H{"tenant": tenantID})
+		})
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultTenant  string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "tenant of the header",
+			headers:        map[string]string{"X-Tenant-ID": "brand-a"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a"}`,
+		},
+		{
+			name:           "default tenant",
+			defaultTenant:  "brand-default",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-default"}`,
+		},
+		{
+			name:           "header over the default tenant",
+			defaultTenant:  "brand-default",
+			headers:        map[string]string{"X-Tenant-ID": "brand-a"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a"}`,
+		},
+		{
+			name:           "missing tenant",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"MISSING_TENANT","message":"missing tenant identifier"}`,
+		},
+		{
+			name:           "invalid tenant",
+			headers:        map[string]string{"X-Tenant-ID": "brand a"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_TENANT","message":"invalid tenant identifier"}`,
+		},
+		{
+			name:           "bound tenant",
+			defaultTenant:  "brand-default",
+			headers:        map[string]string{"X-Bound-Tenant": "brand-a"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a"}`,
+		},
+		{
+			name:           "bound tenant sent in the header",
+			headers:        map[string]string{"X-Bound-Tenant": "brand-a", "X-Tenant-ID": "brand-a"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-a"}`,
+		},
+		{
+			name:           "other tenant than the bound one",
+			headers:        map[string]string{"X-Bound-Tenant": "brand-a", "X-Tenant-ID": "brand-b", "X-Permissions": string(authz.ReadOrders)},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"code":"FORBIDDEN","message":"insufficient permissions"}`,
+		},
+		{
+			name:           "other tenant with the cross-tenant permission",
+			headers:        map[string]string{"X-Bound-Tenant": "brand-a", "X-Tenant-ID": "brand-b", "X-Permissions": string(authz.AllTenants)},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"brand-b"}`,
+		},
+		{
+			name:           "invalid tenant with the cross-tenant permission",
+			headers:        map[string]string{"X-Bound-Tenant": "brand-a", "X-Tenant-ID": "brand b", "X-Permissions": string(authz.AllTenants)},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":"INVALID_TENANT","message":"invalid tenant identifier"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set("Accept", "application/json")
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			setupRouter(tt.defaultTenant).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}